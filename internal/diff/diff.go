@@ -0,0 +1,163 @@
+// Package diff provides a unified diff of texts.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines around changes in a hunk.
+const context = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Diff returns the unified diff between old and new.
+// It returns nil if old and new are identical.
+func Diff(oldName string, old []byte, newName string, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	ops := edits(lines(old), lines(new))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+
+	// Positions of ops in the old and new lines, 1-origin.
+	x, y := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			x++
+			y++
+			i++
+			continue
+		}
+		// Extend the hunk while changes are separated by at most 2*context unchanged lines.
+		start := max(i-context, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			n := 0
+			for end+n < len(ops) && ops[end+n].kind == opEqual {
+				n++
+			}
+			if end+n == len(ops) || n > 2*context {
+				end += min(n, context)
+				break
+			}
+			end += n
+		}
+		x0, y0 := x-(i-start), y-(i-start)
+		var nx, ny int
+		for _, o := range ops[start:end] {
+			if o.kind != opInsert {
+				nx++
+			}
+			if o.kind != opDelete {
+				ny++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(x0, nx), hunkRange(y0, ny))
+		for _, o := range ops[start:end] {
+			switch o.kind {
+			case opEqual:
+				buf.WriteString(" ")
+			case opDelete:
+				buf.WriteString("-")
+			case opInsert:
+				buf.WriteString("+")
+			}
+			buf.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		for _, o := range ops[i:end] {
+			if o.kind != opInsert {
+				x++
+			}
+			if o.kind != opDelete {
+				y++
+			}
+		}
+		i = end
+	}
+	return buf.Bytes()
+}
+
+// hunkRange formats the range of a hunk.
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		// An empty range refers to the line just before it.
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
+
+// lines splits b into lines; each line has the trailing newline except the last one if b doesn't end with it.
+func lines(b []byte) []string {
+	var a []string
+	for line := range strings.Lines(string(b)) {
+		a = append(a, line)
+	}
+	return a
+}
+
+// edits returns the shortest edit script that transforms a into b, based on the longest common subsequence.
+func edits(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
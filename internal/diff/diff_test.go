@@ -0,0 +1,29 @@
+package diff
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"", "a\n", "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+		{"a\nb", "a\nc\n", "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n"},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			"--- old\n+++ new\n" +
+				"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+				"@@ -9,4 +9,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+	}
+	for _, tt := range tests {
+		s := string(Diff("old", []byte(tt.old), "new", []byte(tt.new)))
+		if s != tt.want {
+			t.Errorf("Diff(%q, %q) = %q; want %q", tt.old, tt.new, s, tt.want)
+		}
+	}
+}
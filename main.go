@@ -6,18 +6,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"go/doc"
-	"go/doc/comment"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
-	"golang.org/x/tools/go/packages"
-
-	"github.com/lufia/godoc2man/internal/language"
+	"github.com/lufia/godoc2man/man"
 )
 
 func usage() {
@@ -28,10 +27,21 @@ func usage() {
 }
 
 var (
-	langFlag = flag.String("lang", "en", "specify the `lang`uage code that is used for GoDoc document")
-	flagFlag = flag.String("flag", "none", "generate options section from sources with static analysis; `pkg` is std or none")
-	dirFlag  = flag.String("dir", "man", "specify the output `dir`ectory")
-	tagsFlag = flag.String("tags", "", "comma-separated list of the build `tag`")
+	langFlag   = flag.String("lang", "en", "specify the `lang`uage code that is used for GoDoc document")
+	flagFlag   = flag.String("flag", "none", "generate options section from sources with static analysis; `pkg` is std, pflag, cobra, urfave, kong or none")
+	dirFlag    = flag.String("dir", "man", "specify the output `dir`ectory")
+	tagsFlag   = flag.String("tags", "", "comma-separated list of the build `tag`")
+	sortFlag   = flag.String("sort", "source", "sort options by `key`; key is name or source")
+	formatFlag = flag.String("format", "man", "output `format`; format is man, mdoc, markdown, html or json")
+
+	completionFlag = flag.String("completion", "", "comma-separated list of the `shell`s to generate completion scripts for; shell is bash, zsh or fish")
+	failfastFlag   = flag.Bool("failfast", false, "stop at the first package that fails")
+	checkFlag      = flag.Bool("check", false, "check whether the files under -dir are up to date without writing them; print the differences and exit with non-zero status if they are not; because manuals are dated by the last commit that changes the package, they have to be regenerated after each such commit")
+	sourceFlag     = flag.String("source", "", "the `source` of the manuals shown in the footer; the default is the module path and its version")
+	manualFlag     = flag.String("manual", "", "the `title` of the manual shown in the header")
+	versionFlag    = flag.String("version", "", "override the `version` of the modules shown in the footer")
+	versionSecFlag = flag.Bool("versionsection", false, "append VERSION section to manuals if the version is known")
+	outputFlag     = flag.String("o", "", "write the manual of a single package into `file` instead of -dir; \"-\" means the standard output")
 )
 
 func main() {
@@ -41,103 +51,123 @@ func main() {
 	flag.Usage = usage
 	flag.Parse()
 
-	if flag.NArg() == 0 {
-		Run(".")
-	} else {
-		for _, name := range flag.Args() {
-			Run(name)
-		}
+	c := &man.Config{
+		Lang:           *langFlag,
+		Flag:           *flagFlag,
+		Tags:           splitList(*tagsFlag),
+		Sort:           *sortFlag,
+		Format:         *formatFlag,
+		Completions:    splitList(*completionFlag),
+		Dir:            *dirFlag,
+		Source:         *sourceFlag,
+		Manual:         *manualFlag,
+		Version:        *versionFlag,
+		VersionSection: *versionSecFlag,
+		FailFast:       *failfastFlag,
+		Warn:           func(msg string) { log.Println(msg) },
 	}
-}
-
-func Run(name string) {
-	c := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedFiles |
-			packages.NeedImports |
-			packages.NeedDeps |
-			packages.NeedTypes |
-			packages.NeedSyntax |
-			packages.NeedTypesInfo,
-	}
-	if *tagsFlag != "" {
-		c.BuildFlags = append(c.BuildFlags, "-tags", *tagsFlag)
-	}
-	pkgs, err := packages.Load(c, name)
-	if err != nil {
-		log.Fatalln(err)
+	if *checkFlag && *outputFlag != "" {
+		log.Fatalln("-check and -o are mutually exclusive")
 	}
-	if packages.PrintErrors(pkgs) > 0 {
-		log.Fatalln("too many errors")
+	if *checkFlag || *outputFlag != "" {
+		c.Dir = ""
 	}
-	for _, pkg := range pkgs {
-		p, err := doc.NewFromFiles(pkg.Fset, pkg.Syntax, pkg.ID)
-		if err != nil {
-			log.Fatalln("parsing documents:", err)
-		}
-
-		section := manualSection(pkg.Name)
-		f, err := outputFile(*dirFlag, pkg.ID, section)
-		if err != nil {
-			log.Fatalln("failed to create a file:", err)
-		}
-
-		s, err := language.String(*langFlag, p.Doc)
+	files, err := man.Generate(context.Background(), c, flag.Args()...)
+	var e *man.ConfigError
+	if errors.As(err, &e) {
+		log.Fatalf("-%s=%s is not supported\n", configFlags[e.Field], e.Value)
+	}
+	failed := err != nil
+	if err != nil {
+		printErrors(err)
+	}
+	if *checkFlag {
+		d, err := man.Diff(*dirFlag, files)
 		if err != nil {
-			log.Fatalf("failed to transform to language '%s': %v", *langFlag, err)
+			log.Fatalln(err)
 		}
-		var parser comment.Parser
-		doc := parser.Parse(s)
-		printer := NewPrinter(pkg.Fset, pkg.ID, section, f)
-		if pkg.Name == "main" {
-			flags := retrieveFlags(pkg)
-			printer.Command(p, doc, flags)
-		} else {
-			printer.Library(p, doc)
+		if len(d) > 0 {
+			os.Stdout.Write(d)
+			log.Println("files are out of date")
+			failed = true
 		}
-		if err := printer.Err(); err != nil {
-			log.Fatalln(err)
+	}
+	if *outputFlag != "" && len(files) > 0 {
+		if err := writeOutput(*outputFlag, files, matchedPackages(files, err)); err != nil {
+			log.Println(err)
+			failed = true
 		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
 
-		if err := f.Sync(); err != nil {
-			log.Fatalln(err)
+// configFlags maps fields of man.Config to the flags that set them.
+var configFlags = map[string]string{
+	"Flag":        "flag",
+	"Sort":        "sort",
+	"Format":      "format",
+	"Completions": "completion",
+}
+
+// matchedPackages returns the import paths of the packages that are generated into files or failed with err.
+func matchedPackages(files []*man.File, err error) []string {
+	var pkgs []string
+	for _, f := range files {
+		if f.Package != "" && !slices.Contains(pkgs, f.Package) {
+			pkgs = append(pkgs, f.Package)
 		}
-		f.Close()
 	}
+	errs := []error{err}
+	if e, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = e.Unwrap()
+	}
+	for _, err := range errs {
+		var e *man.PackageError
+		if errors.As(err, &e) && !slices.Contains(pkgs, e.Path) {
+			pkgs = append(pkgs, e.Path)
+		}
+	}
+	return pkgs
 }
 
-func manualSection(name string) string {
-	if name == "main" {
-		return "1"
+// writeOutput writes the manual of a single package into file; "-" means the standard output.
+// Manuals of subcommands are not written.
+// It refuses to write anything if pkgs, the packages that the patterns match, has more than one package.
+func writeOutput(file string, files []*man.File, pkgs []string) error {
+	if len(pkgs) > 1 {
+		return fmt.Errorf("-o requires a single package, but %d packages match: %s", len(pkgs), strings.Join(pkgs, ", "))
+	}
+	i := slices.IndexFunc(files, func(f *man.File) bool {
+		return f.Page != nil
+	})
+	if i < 0 {
+		return errors.New("-o: no manuals are generated")
 	}
-	return "3"
+	if file == "-" {
+		_, err := files[i].WriteTo(os.Stdout)
+		return err
+	}
+	return man.WriteFile(file, files[i].Data)
 }
 
-func outputFile(base, pkgPath, section string) (*os.File, error) {
-	dir := filepath.Join(base, "man"+section)
-	err := os.MkdirAll(dir, 0755)
-	if err != nil && os.IsExist(err) {
-		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
-	}
-	name := strings.ReplaceAll(pkgPath, "/", "-") + "." + section
-	file := filepath.Join(dir, name)
-	f, err := os.Create(file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create %s: %w", file, err)
+// printErrors prints each error joined into err, then prints the number of them.
+func printErrors(err error) {
+	errs := []error{err}
+	if e, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = e.Unwrap()
 	}
-	return f, nil
+	for _, err := range errs {
+		log.Println(err)
+	}
+	log.Printf("%d error(s) occurred\n", len(errs))
 }
 
-func retrieveFlags(p *packages.Package) []*Flag {
-	var flags []*Flag
-	switch *flagFlag {
-	default:
-		log.Printf("-flag=%s is not supported; ignored\n", *flagFlag)
-	case "none":
-	case "std":
-		for f := range FindFlags(p.TypesInfo, p.Fset, p.Syntax) {
-			flags = append(flags, f)
-		}
+// splitList splits the comma-separated list s.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
 	}
-	return flags
+	return strings.Split(s, ",")
 }
@@ -0,0 +1,185 @@
+package man
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const cobraPath = "github.com/spf13/cobra"
+
+var cobraFlagSet = &flagSetKind{
+	isSet: func(t types.Type) bool {
+		return isCommand(t) || isPFlagSet(t)
+	},
+	origin: func(s *flagSets, x ast.Expr) (string, bool) {
+		if name, ok := newPFlagSetName(s.info, x); ok {
+			return name, true
+		}
+		switch x := x.(type) {
+		case *ast.CompositeLit:
+			if isCommand(s.info.TypeOf(x)) {
+				return commandName(exprStr(s.info, fieldValue(x, "Use"))), true
+			}
+		case *ast.CallExpr:
+			fn, ok := typeutil.Callee(s.info, x).(*types.Func)
+			if !ok || !isCommandMethod(fn) {
+				return "", false
+			}
+			switch fn.Name() {
+			case "Flags", "PersistentFlags", "LocalFlags":
+				return s.NameOf(x.Fun.(*ast.SelectorExpr).X)
+			}
+		}
+		return "", false
+	},
+}
+
+// cobraPersistentFlagSet names only the flag sets returned by PersistentFlags of cobra.Command.
+var cobraPersistentFlagSet = &flagSetKind{
+	isSet: cobraFlagSet.isSet,
+	origin: func(s *flagSets, x ast.Expr) (string, bool) {
+		switch x := x.(type) {
+		case *ast.CompositeLit:
+			if isCommand(s.info.TypeOf(x)) {
+				return commandName(exprStr(s.info, fieldValue(x, "Use"))), true
+			}
+		case *ast.CallExpr:
+			fn, ok := typeutil.Callee(s.info, x).(*types.Func)
+			if ok && isCommandMethod(fn) && fn.Name() == "PersistentFlags" {
+				return s.NameOf(x.Fun.(*ast.SelectorExpr).X)
+			}
+		}
+		return "", false
+	},
+}
+
+type cobraCommand struct {
+	name  string
+	short string
+	long  string
+}
+
+// findCobraCommands retrieves commands and their flags defined with github.com/spf13/cobra package.
+// It returns the root command that has its flags and subcommands in declaration order.
+// Persistent flags of the root command are also listed in the flags of each subcommand.
+// Arguments that are not constants are reported to warn unless it is nil.
+func findCobraCommands(info *types.Info, fset *token.FileSet, files []*ast.File, warn func(msg string)) *commandInfo {
+	var (
+		sets     = newFlagSets(cobraFlagSet, info, files)
+		cmds     []*cobraCommand
+		children = make(map[string]bool)
+	)
+	for _, f := range files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.CompositeLit:
+				if !isCommand(info.TypeOf(n)) {
+					break
+				}
+				cmds = append(cmds, &cobraCommand{
+					name:  commandName(exprStr(info, fieldValue(n, "Use"))),
+					short: exprStr(info, fieldValue(n, "Short")),
+					long:  exprStr(info, fieldValue(n, "Long")),
+				})
+			case *ast.CallExpr:
+				fn, ok := typeutil.Callee(info, n).(*types.Func)
+				if !ok || !isCommandMethod(fn) || fn.Name() != "AddCommand" {
+					break
+				}
+				for _, arg := range n.Args {
+					if name, ok := sets.NameOf(arg); ok {
+						children[name] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	var (
+		cmd  commandInfo
+		root string
+	)
+	if i := slices.IndexFunc(cmds, func(c *cobraCommand) bool { return !children[c.name] }); i >= 0 {
+		root = cmds[i].name
+		cmd.Synopsis = cmds[i].short
+		cmd.Doc = cmds[i].long
+	}
+	flags := collectPFlags(cobraFlagSet, info, fset, files, warn)
+	for _, flg := range flags {
+		if flg.FlagSet == root {
+			flg.FlagSet = ""
+		}
+	}
+	own, subcmds := groupFlags("", flags)
+
+	var a []*subcommand
+	for _, c := range cmds {
+		if c.name == "" || c.name == root {
+			continue
+		}
+		a = append(a, &subcommand{
+			Name:     c.name,
+			Synopsis: c.short,
+			Doc:      c.long,
+		})
+	}
+	cmd.Flags = own
+	cmd.Subcommands = mergeSubcommands(a, subcmds)
+
+	// Cobra accepts the persistent flags of the root command in its subcommands too.
+	// Collecting them again with cobraPersistentFlagSet leaves the others without the name.
+	var persistent []string
+	if root != "" {
+		for _, flg := range collectPFlags(cobraPersistentFlagSet, info, fset, files, nil) {
+			if flg.FlagSet == root {
+				persistent = append(persistent, flg.Name)
+			}
+		}
+	}
+	for _, flg := range own {
+		if !slices.Contains(persistent, flg.Name) {
+			continue
+		}
+		for _, sub := range cmd.Subcommands {
+			sub.Flags = append(sub.Flags, flg)
+		}
+	}
+	return &cmd
+}
+
+// commandName returns the name of the command from Use field of cobra.Command.
+func commandName(use string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(use), " ")
+	return name
+}
+
+// fieldValue returns the value of the field name in the composite literal lit.
+func fieldValue(lit *ast.CompositeLit, name string) ast.Expr {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == name {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// isCommand reports whether t is cobra.Command or a pointer to it.
+func isCommand(t types.Type) bool {
+	return t != nil && isNamed(t, cobraPath, "Command")
+}
+
+// isCommandMethod reports whether fn is a method of cobra.Command.
+func isCommandMethod(fn *types.Func) bool {
+	recv := fn.Signature().Recv()
+	return recv != nil && isCommand(recv.Type())
+}
@@ -0,0 +1,316 @@
+package man
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// completionFiles maps the shells to the file names of the completion scripts for the command name.
+var completionFiles = map[string]func(name string) string{
+	"bash": func(name string) string { return name + ".bash" },
+	"zsh":  func(name string) string { return "_" + name },
+	"fish": func(name string) string { return name + ".fish" },
+}
+
+// argKind represents what kind of argument the flag takes.
+type argKind int
+
+const (
+	noArg argKind = iota
+	valueArg
+	fileArg
+	dirArg
+)
+
+// flagArg returns the kind of the argument of flg that is guessed from its placeholder.
+func flagArg(flg *Flag) argKind {
+	if flg.Kind.IsSwitch() {
+		return noArg
+	}
+	switch strings.ToLower(flg.Placeholder) {
+	case "file", "files", "filename", "path", "paths":
+		return fileArg
+	case "dir", "dirs", "directory":
+		return dirArg
+	}
+	return valueArg
+}
+
+// completionNames returns option names of flg with leading dashes.
+func completionNames(flg *Flag) []string {
+	if flg.Long {
+		return optionNames(flg)
+	}
+	return []string{"-" + flg.Name}
+}
+
+// writeCompletion writes the completion script of shell for the command name.
+func writeCompletion(w io.Writer, shell, name string, flags []*Flag, subcmds []*subcommand) error {
+	var b strings.Builder
+	switch shell {
+	case "bash":
+		writeBashCompletion(&b, name, flags, subcmds)
+	case "zsh":
+		writeZshCompletion(&b, name, flags, subcmds)
+	case "fish":
+		writeFishCompletion(&b, name, flags, subcmds)
+	default:
+		return fmt.Errorf("man: unsupported shell %q", shell)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var nonIdentRune = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// shellFuncName returns the name of the shell function for the command name.
+func shellFuncName(name string) string {
+	return "_" + nonIdentRune.ReplaceAllString(name, "_")
+}
+
+func writeBashCompletion(w io.Writer, name string, flags []*Flag, subcmds []*subcommand) {
+	fn := shellFuncName(name)
+	fmt.Fprintf(w, "# bash completion for %s\n\n", name)
+	fmt.Fprintf(w, "%s()\n{\n", fn)
+	fmt.Fprintln(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]}")
+	fmt.Fprintln(w, "\tlocal prev=${COMP_WORDS[COMP_CWORD-1]}")
+	fmt.Fprintln(w, "\tlocal cmd= opts= i")
+	if len(subcmds) > 0 {
+		names := make([]string, len(subcmds))
+		for i, sub := range subcmds {
+			names[i] = sub.Name
+		}
+		fmt.Fprintln(w, "\tfor ((i = 1; i < COMP_CWORD; i++)); do")
+		fmt.Fprintln(w, "\t\tcase ${COMP_WORDS[i]} in")
+		fmt.Fprintf(w, "\t\t%s)\n", strings.Join(names, "|"))
+		fmt.Fprintln(w, "\t\t\tcmd=${COMP_WORDS[i]}")
+		fmt.Fprintln(w, "\t\t\tbreak")
+		fmt.Fprintln(w, "\t\t\t;;")
+		fmt.Fprintln(w, "\t\tesac")
+		fmt.Fprintln(w, "\tdone")
+	}
+
+	// Complete the argument of the flag.
+	fmt.Fprintln(w, "\tcase $cmd,$prev in")
+	writeBashArgCases(w, "", flags)
+	for _, sub := range subcmds {
+		writeBashArgCases(w, sub.Name, sub.Flags)
+	}
+	fmt.Fprintln(w, "\tesac")
+
+	fmt.Fprintln(w, "\tcase $cmd in")
+	fmt.Fprintln(w, "\t'')")
+	fmt.Fprintf(w, "\t\topts=%s\n", shellQuote(strings.Join(bashOptions(flags), " ")))
+	fmt.Fprintln(w, "\t\t;;")
+	for _, sub := range subcmds {
+		fmt.Fprintf(w, "\t%s)\n", shellQuote(sub.Name))
+		fmt.Fprintf(w, "\t\topts=%s\n", shellQuote(strings.Join(bashOptions(sub.Flags), " ")))
+		fmt.Fprintln(w, "\t\t;;")
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "\tif [[ $cur == -* ]]; then")
+	fmt.Fprintln(w, "\t\tCOMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))")
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	if len(subcmds) > 0 {
+		names := make([]string, len(subcmds))
+		for i, sub := range subcmds {
+			names[i] = sub.Name
+		}
+		fmt.Fprintln(w, "\tif [[ -z $cmd ]]; then")
+		fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(names, " ")))
+		fmt.Fprintln(w, "\tfi")
+	}
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "complete -o default -F %s %s\n", fn, name)
+}
+
+func bashOptions(flags []*Flag) []string {
+	var a []string
+	for _, flg := range flags {
+		a = append(a, completionNames(flg)...)
+	}
+	return a
+}
+
+func writeBashArgCases(w io.Writer, cmd string, flags []*Flag) {
+	for _, flg := range flags {
+		kind := flagArg(flg)
+		if kind == noArg {
+			continue
+		}
+		var patterns []string
+		for _, s := range completionNames(flg) {
+			patterns = append(patterns, shellQuote(cmd+","+s))
+		}
+		fmt.Fprintf(w, "\t%s)\n", strings.Join(patterns, "|"))
+		switch kind {
+		case fileArg:
+			fmt.Fprintln(w, "\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))")
+		case dirArg:
+			fmt.Fprintln(w, "\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))")
+		default:
+			fmt.Fprintln(w, "\t\tCOMPREPLY=()")
+		}
+		fmt.Fprintln(w, "\t\treturn")
+		fmt.Fprintln(w, "\t\t;;")
+	}
+}
+
+func writeZshCompletion(w io.Writer, name string, flags []*Flag, subcmds []*subcommand) {
+	fn := shellFuncName(name)
+	fmt.Fprintf(w, "#compdef %s\n\n", name)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	if len(subcmds) == 0 {
+		fmt.Fprint(w, "\t_arguments -s")
+		writeZshSpecs(w, "\t\t", flags)
+		fmt.Fprintln(w, " \\\n\t\t'*:file:_files'")
+		fmt.Fprintln(w, "}")
+		fmt.Fprintf(w, "\n%s \"$@\"\n", fn)
+		return
+	}
+	fmt.Fprintln(w, "\tlocal line state")
+	fmt.Fprint(w, "\t_arguments -s -C")
+	writeZshSpecs(w, "\t\t", flags)
+	fmt.Fprintln(w, " \\\n\t\t'1: :->cmds' \\\n\t\t'*:: :->args'")
+	fmt.Fprintln(w, "\tcase $state in")
+	fmt.Fprintln(w, "\tcmds)")
+	fmt.Fprint(w, "\t\t_values 'command'")
+	for _, sub := range subcmds {
+		fmt.Fprintf(w, " \\\n\t\t\t%s", shellQuote(zshEscape(sub.Name)+"["+zshEscape(sub.Synopsis)+"]"))
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "\t\t;;")
+	fmt.Fprintln(w, "\targs)")
+	fmt.Fprintln(w, "\t\tcase $line[1] in")
+	for _, sub := range subcmds {
+		fmt.Fprintf(w, "\t\t%s)\n", shellQuote(sub.Name))
+		fmt.Fprint(w, "\t\t\t_arguments -s")
+		writeZshSpecs(w, "\t\t\t\t", sub.Flags)
+		fmt.Fprintln(w, " \\\n\t\t\t\t'*:file:_files'")
+		fmt.Fprintln(w, "\t\t\t;;")
+	}
+	fmt.Fprintln(w, "\t\tesac")
+	fmt.Fprintln(w, "\t\t;;")
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintf(w, "\n%s \"$@\"\n", fn)
+}
+
+// writeZshSpecs writes the option specs of flags for _arguments.
+func writeZshSpecs(w io.Writer, indent string, flags []*Flag) {
+	for _, flg := range flags {
+		names := completionNames(flg)
+		var exclusion string
+		if len(names) > 1 {
+			exclusion = "(" + strings.Join(names, " ") + ")"
+		}
+		desc := "[" + zshEscape(flg.Usage) + "]"
+		var arg string
+		switch kind := flagArg(flg); kind {
+		case noArg:
+		default:
+			placeholder := flg.Placeholder
+			if placeholder == "" {
+				placeholder = "value"
+			}
+			arg = ":" + zshEscape(placeholder) + ":"
+			switch kind {
+			case fileArg:
+				arg += "_files"
+			case dirArg:
+				arg += "_files -/"
+			}
+		}
+		for _, s := range names {
+			var suffix string
+			switch {
+			case arg == "":
+			case flg.Long && !strings.HasPrefix(s, "--"):
+				// Shorthand accepts -ovalue as well as -o value.
+				suffix = "+"
+			default:
+				// Accepts -name=value as well as -name value.
+				suffix = "="
+			}
+			fmt.Fprintf(w, " \\\n%s%s", indent, shellQuote(exclusion+s+suffix+desc+arg))
+		}
+	}
+}
+
+func writeFishCompletion(w io.Writer, name string, flags []*Flag, subcmds []*subcommand) {
+	fmt.Fprintf(w, "# fish completion for %s\n\n", name)
+	var cond string
+	if len(subcmds) > 0 {
+		fmt.Fprintf(w, "complete -c %s -f\n", shellQuote(name))
+		for _, sub := range subcmds {
+			fmt.Fprintf(w, "complete -c %s -n __fish_use_subcommand -a %s", shellQuote(name), shellQuote(sub.Name))
+			if sub.Synopsis != "" {
+				fmt.Fprintf(w, " -d %s", shellQuote(sub.Synopsis))
+			}
+			fmt.Fprintln(w, "")
+		}
+		cond = "__fish_use_subcommand"
+	}
+	writeFishFlags(w, name, cond, flags)
+	for _, sub := range subcmds {
+		writeFishFlags(w, name, "__fish_seen_subcommand_from "+sub.Name, sub.Flags)
+	}
+}
+
+func writeFishFlags(w io.Writer, name, cond string, flags []*Flag) {
+	for _, flg := range flags {
+		fmt.Fprintf(w, "complete -c %s", shellQuote(name))
+		if cond != "" {
+			fmt.Fprintf(w, " -n %s", shellQuote(cond))
+		}
+		for _, s := range completionNames(flg) {
+			switch {
+			case strings.HasPrefix(s, "--"):
+				fmt.Fprintf(w, " -l %s", shellQuote(s[2:]))
+			case len(s) == 2:
+				fmt.Fprintf(w, " -s %s", shellQuote(s[1:]))
+			default:
+				fmt.Fprintf(w, " -o %s", shellQuote(s[1:]))
+			}
+		}
+		switch flagArg(flg) {
+		case valueArg:
+			fmt.Fprint(w, " -x")
+		case fileArg:
+			fmt.Fprint(w, " -r -F")
+		case dirArg:
+			fmt.Fprint(w, " -x -a '(__fish_complete_directories)'")
+		}
+		if flg.Usage != "" {
+			fmt.Fprintf(w, " -d %s", shellQuote(flg.Usage))
+		}
+		fmt.Fprintln(w, "")
+	}
+}
+
+var zshEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"[", `\[`,
+	"]", `\]`,
+	":", `\:`,
+)
+
+// zshEscape escapes characters that have special meanings in the specs of _arguments.
+func zshEscape(s string) string {
+	return zshEscaper.Replace(s)
+}
+
+// shellQuote quotes s with single quotes if needed.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsFunc(s, func(c rune) bool {
+		return !slices.Contains([]rune("-_.,/=+%@"), c) &&
+			!('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9')
+	}) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,60 @@
+package man
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlagArg(t *testing.T) {
+	tests := []struct {
+		flg  *Flag
+		want argKind
+	}{
+		{&Flag{Name: "v", Kind: BoolFlag}, noArg},
+		{&Flag{Name: "o", Placeholder: "file", Kind: ValueFlag}, fileArg},
+		{&Flag{Name: "C", Placeholder: "dir", Kind: ValueFlag}, dirArg},
+		{&Flag{Name: "n", Placeholder: "count", Kind: ValueFlag}, valueArg},
+	}
+	for _, tt := range tests {
+		if k := flagArg(tt.flg); k != tt.want {
+			t.Errorf("flagArg(%s) = %v; want %v", tt.flg.Name, k, tt.want)
+		}
+	}
+}
+
+func TestWriteCompletion(t *testing.T) {
+	flags := []*Flag{
+		{Name: "verbose", Shorthand: "v", Long: true, Kind: BoolFlag, Usage: "enable verbose output"},
+		{Name: "output", Shorthand: "o", Long: true, Kind: ValueFlag, Placeholder: "file", Usage: "write the output to file"},
+	}
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"bash", []string{
+			"\topts='-v --verbose -o --output'\n",
+			"\t,-o|,--output)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n",
+			"complete -o default -F _cmd cmd\n",
+		}},
+		{"zsh", []string{
+			"#compdef cmd\n",
+			"'(-v --verbose)--verbose[enable verbose output]'",
+			"'(-o --output)-o+[write the output to file]:file:_files'",
+		}},
+		{"fish", []string{
+			"complete -c cmd -s v -l verbose -d 'enable verbose output'\n",
+			"complete -c cmd -s o -l output -r -F -d 'write the output to file'\n",
+		}},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		if err := writeCompletion(&b, tt.shell, "cmd", flags, nil); err != nil {
+			t.Fatalf("writeCompletion(%s): %v", tt.shell, err)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(b.String(), s) {
+				t.Errorf("writeCompletion(%s) does not contain %q:\n%s", tt.shell, s, b.String())
+			}
+		}
+	}
+}
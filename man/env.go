@@ -0,0 +1,112 @@
+package man
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// envVar represents an environment variable that the command reads.
+type envVar struct {
+	Name string
+
+	// Doc is the comment written next to the code reading the variable.
+	Doc string
+}
+
+// findEnv retrieves environment variables read by [os.Getenv] or [os.LookupEnv] with constant keys.
+// The comment on the statement that reads the variable is used as its document.
+func findEnv(info *types.Info, fset *token.FileSet, files []*ast.File) []*envVar {
+	var env []*envVar
+	for _, f := range files {
+		cmap := ast.NewCommentMap(fset, f, f.Comments)
+		var stack []ast.Node
+		ast.Inspect(f, func(node ast.Node) bool {
+			if node == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			stack = append(stack, node)
+			call, ok := node.(*ast.CallExpr)
+			if !ok || !isGetenv(typeutil.Callee(info, call)) || len(call.Args) != 1 {
+				return true
+			}
+			name, ok := constStr(info, call.Args[0])
+			if !ok || name == "" {
+				return true
+			}
+			i := slices.IndexFunc(env, func(e *envVar) bool {
+				return e.Name == name
+			})
+			if i < 0 {
+				i = len(env)
+				env = append(env, &envVar{Name: name})
+			}
+			if env[i].Doc == "" {
+				env[i].Doc = nearestComment(cmap, stack)
+			}
+			return true
+		})
+	}
+	return env
+}
+
+func isGetenv(obj types.Object) bool {
+	if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() != "os" {
+		return false
+	}
+	return obj.Name() == "Getenv" || obj.Name() == "LookupEnv"
+}
+
+// nearestComment returns the text of the comment associated with the innermost node of stack.
+// It doesn't look beyond the enclosing block or function.
+func nearestComment(cmap ast.CommentMap, stack []ast.Node) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.BlockStmt, *ast.FuncDecl, *ast.FuncLit, *ast.File:
+			return ""
+		}
+		var a []string
+		for _, c := range cmap[stack[i]] {
+			a = append(a, strings.TrimSpace(c.Text()))
+		}
+		if len(a) > 0 {
+			return strings.Join(a, " ")
+		}
+	}
+	return ""
+}
+
+// flagEnv returns environment variables that can set flags.
+func flagEnv(flags []*Flag, subcmds []*subcommand) []*envVar {
+	var env []*envVar
+	add := func(flags []*Flag) {
+		for _, flg := range flags {
+			for _, name := range flg.Env {
+				env = append(env, &envVar{
+					Name: name,
+					Doc:  flg.Usage + " (same as " + optionNames(flg)[0] + ")",
+				})
+			}
+		}
+	}
+	add(flags)
+	for _, sub := range subcmds {
+		add(sub.Flags)
+	}
+	return env
+}
+
+// mergeEnv appends variables of b that are not in a.
+func mergeEnv(a, b []*envVar) []*envVar {
+	for _, e := range b {
+		if !slices.ContainsFunc(a, func(x *envVar) bool { return x.Name == e.Name }) {
+			a = append(a, e)
+		}
+	}
+	return a
+}
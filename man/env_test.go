@@ -0,0 +1,23 @@
+package man
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindEnv(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/env")
+	env := findEnv(p.TypesInfo, p.Fset, sortFiles(p.Fset, p.Syntax))
+	want := []envVar{
+		{Name: "HOME", Doc: "home is the base directory."},
+		{Name: "ENV_CONFIG", Doc: "ENV_CONFIG is a path to the configuration file."},
+		{Name: "EDITOR"},
+	}
+	a := make([]envVar, len(env))
+	for i, e := range env {
+		a[i] = *e
+	}
+	if !reflect.DeepEqual(a, want) {
+		t.Errorf("findEnv() = %v; want %v", a, want)
+	}
+}
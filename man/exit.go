@@ -0,0 +1,257 @@
+package man
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// exitStatus represents an exit status of the command.
+type exitStatus struct {
+	Code int
+
+	// Doc is the comment of the constant of the code, or the comment next to the call.
+	Doc string
+}
+
+// findExitStatus retrieves exit statuses passed to [os.Exit] or implied by [log.Fatal].
+// If the argument of os.Exit calls a function of the package, such as os.Exit(run()),
+// the statuses that the function returns are retrieved.
+// If the argument is a variable of a named type, all constants of the type are retrieved.
+// If the status is a constant, its comment describes the status.
+// The result is sorted by the code, and it always contains 0 if any status is found.
+func findExitStatus(info *types.Info, fset *token.FileSet, files []*ast.File) []*exitStatus {
+	x := &exitFinder{
+		info:     info,
+		funcs:    make(map[types.Object]*ast.FuncDecl),
+		cmaps:    make(map[*ast.FuncDecl]ast.CommentMap),
+		visiting: make(map[types.Object]bool),
+	}
+	x.docs, x.consts = constDocs(info, files)
+	for _, f := range files {
+		cmap := ast.NewCommentMap(fset, f, f.Comments)
+		for _, decl := range f.Decls {
+			if d, ok := decl.(*ast.FuncDecl); ok && d.Body != nil {
+				x.funcs[info.Defs[d.Name]] = d
+				x.cmaps[d] = cmap
+			}
+		}
+	}
+	for _, f := range files {
+		cmap := ast.NewCommentMap(fset, f, f.Comments)
+		var stack []ast.Node
+		ast.Inspect(f, func(node ast.Node) bool {
+			if node == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			stack = append(stack, node)
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			switch obj := typeutil.Callee(info, call); {
+			case isFunc(obj, "os", "Exit") && len(call.Args) == 1:
+				x.exprStatus(call.Args[0], nearestComment(cmap, stack))
+			case isFunc(obj, "log", "Fatal", "Fatalf", "Fatalln"):
+				x.add(1, "")
+			}
+			return true
+		})
+	}
+	a := x.status
+	if len(a) == 0 {
+		return nil
+	}
+	for _, s := range a {
+		if s.Doc == "" {
+			s.Doc = defaultExitDoc(s.Code)
+		}
+	}
+	if !slices.ContainsFunc(a, func(s *exitStatus) bool { return s.Code == 0 }) {
+		a = append(a, &exitStatus{Code: 0, Doc: defaultExitDoc(0)})
+	}
+	slices.SortFunc(a, func(x, y *exitStatus) int {
+		return x.Code - y.Code
+	})
+	return a
+}
+
+// exitFinder holds the state of findExitStatus.
+type exitFinder struct {
+	info   *types.Info
+	docs   map[types.Object]string
+	consts []*types.Const
+	funcs  map[types.Object]*ast.FuncDecl
+	cmaps  map[*ast.FuncDecl]ast.CommentMap
+	status []*exitStatus
+
+	// visiting is the set of functions that are being resolved, to stop recursive calls.
+	visiting map[types.Object]bool
+}
+
+// add appends the status of code unless it is found already.
+// The first non-empty doc describes the status.
+func (x *exitFinder) add(code int, doc string) {
+	i := slices.IndexFunc(x.status, func(s *exitStatus) bool {
+		return s.Code == code
+	})
+	if i < 0 {
+		i = len(x.status)
+		x.status = append(x.status, &exitStatus{Code: code})
+	}
+	if x.status[i].Doc == "" {
+		x.status[i].Doc = doc
+	}
+}
+
+// exprStatus adds the statuses that expr can evaluate to.
+// Doc is the comment next to expr; the comment of the constant precedes it.
+func (x *exitFinder) exprStatus(expr ast.Expr, doc string) {
+	expr = ast.Unparen(expr)
+	if tv := x.info.Types[expr]; tv.Value != nil {
+		code, ok := exitCode(tv.Value)
+		if !ok {
+			return
+		}
+		if s := x.docs[constObject(x.info, expr)]; s != "" {
+			doc = s
+		}
+		x.add(code, doc)
+		return
+	}
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if x.info.Types[call.Fun].IsType() && len(call.Args) == 1 {
+			// conversion such as int(code)
+			x.exprStatus(call.Args[0], doc)
+			return
+		}
+		fn := typeutil.Callee(x.info, call)
+		if d := x.funcs[fn]; d != nil && !x.visiting[fn] {
+			x.visiting[fn] = true
+			x.returnStatus(d)
+			delete(x.visiting, fn)
+			return
+		}
+	}
+	x.typeStatus(x.info.TypeOf(expr))
+}
+
+// returnStatus adds the statuses that the function d returns.
+func (x *exitFinder) returnStatus(d *ast.FuncDecl) {
+	if d.Type.Results.NumFields() != 1 {
+		return
+	}
+	cmap := x.cmaps[d]
+	var stack []ast.Node
+	ast.Inspect(d.Body, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := node.(*ast.FuncLit); ok {
+			// Returns of function literals don't return from d.
+			return false
+		}
+		stack = append(stack, node)
+		if ret, ok := node.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			x.exprStatus(ret.Results[0], nearestComment(cmap, stack))
+		}
+		return true
+	})
+}
+
+// typeStatus adds the constants of t if t is a named type declared in the package.
+func (x *exitFinder) typeStatus(t types.Type) {
+	if _, ok := t.(*types.Named); !ok {
+		return
+	}
+	for _, c := range x.consts {
+		if !types.Identical(c.Type(), t) {
+			continue
+		}
+		if code, ok := exitCode(c.Val()); ok {
+			x.add(code, x.docs[c])
+		}
+	}
+}
+
+func defaultExitDoc(code int) string {
+	switch code {
+	case 0:
+		return "Successful completion."
+	case 1:
+		return "An error occurred."
+	}
+	return ""
+}
+
+func exitCode(v constant.Value) (int, bool) {
+	if v.Kind() != constant.Int {
+		return 0, false
+	}
+	n, ok := constant.Int64Val(v)
+	return int(n), ok
+}
+
+// isFunc reports whether obj is one of functions names of the package pkgPath.
+// Methods, such as (*log.Logger).Fatal, are also matched.
+func isFunc(obj types.Object, pkgPath string, names ...string) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != pkgPath {
+		return false
+	}
+	return slices.Contains(names, fn.Name())
+}
+
+// constObject returns the constant that expr refers to.
+func constObject(info *types.Info, expr ast.Expr) types.Object {
+	switch x := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return info.Uses[x]
+	case *ast.SelectorExpr:
+		return info.Uses[x.Sel]
+	}
+	return nil
+}
+
+// constDocs returns doc comments of constants declared in files,
+// and the constants in declaration order.
+func constDocs(info *types.Info, files []*ast.File) (map[types.Object]string, []*types.Const) {
+	var (
+		docs   = make(map[types.Object]string)
+		consts []*types.Const
+	)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			d, ok := decl.(*ast.GenDecl)
+			if !ok || d.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range d.Specs {
+				spec := spec.(*ast.ValueSpec)
+				doc := spec.Doc.Text()
+				if doc == "" {
+					doc = spec.Comment.Text()
+				}
+				if doc == "" && len(d.Specs) == 1 {
+					doc = d.Doc.Text()
+				}
+				for _, name := range spec.Names {
+					c, ok := info.Defs[name].(*types.Const)
+					if !ok {
+						continue
+					}
+					docs[c] = strings.TrimSpace(doc)
+					consts = append(consts, c)
+				}
+			}
+		}
+	}
+	return docs, consts
+}
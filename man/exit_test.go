@@ -0,0 +1,44 @@
+package man
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindExitStatus(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/exit")
+	status := findExitStatus(p.TypesInfo, p.Fset, sortFiles(p.Fset, p.Syntax))
+	want := []exitStatus{
+		{Code: 0, Doc: "Successful completion."},
+		{Code: 1, Doc: "An error occurred."},
+		{Code: 2, Doc: "exitUsage means the arguments are invalid."},
+		{Code: 3, Doc: "the file is not found"},
+		{Code: 4, Doc: "too many files are passed."},
+	}
+	a := make([]exitStatus, len(status))
+	for i, s := range status {
+		a[i] = *s
+	}
+	if !reflect.DeepEqual(a, want) {
+		t.Errorf("findExitStatus() = %v; want %v", a, want)
+	}
+}
+
+func TestFindExitStatusReturned(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/exitrun")
+	status := findExitStatus(p.TypesInfo, p.Fset, sortFiles(p.Fset, p.Syntax))
+	want := []exitStatus{
+		{Code: 0, Doc: "Successful completion."},
+		{Code: 2, Doc: "exitUsage means the arguments are invalid."},
+		{Code: 3, Doc: "the file is not found."},
+		{Code: 10, Doc: "statusConflict means the file is modified by another process."},
+		{Code: 11, Doc: "statusBusy means the file is locked."},
+	}
+	a := make([]exitStatus, len(status))
+	for i, s := range status {
+		a[i] = *s
+	}
+	if !reflect.DeepEqual(a, want) {
+		t.Errorf("findExitStatus() = %v; want %v", a, want)
+	}
+}
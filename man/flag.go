@@ -0,0 +1,491 @@
+package man
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// FlagKind represents how a flag is specified in the command line.
+type FlagKind int
+
+const (
+	// ValueFlag takes a value, such as "-name=value".
+	ValueFlag FlagKind = iota
+
+	// BoolFlag is a boolean switch that doesn't take a value, such as "-v".
+	BoolFlag
+
+	// CountFlag is a switch that can be repeated to increase the count, such as "-vvv".
+	CountFlag
+)
+
+// IsSwitch reports whether the flag of kind k doesn't take a value.
+func (k FlagKind) IsSwitch() bool {
+	return k == BoolFlag || k == CountFlag
+}
+
+type Flag struct {
+	Name        string
+	Placeholder string
+	Usage       string
+
+	// Kind is the kind of the flag; whether it takes a value or not.
+	Kind FlagKind
+
+	// Shorthand is a one-letter abbreviation of the flag, such as pflag's.
+	Shorthand string
+
+	// Long reports whether the flag is a GNU-style long option that starts with "--".
+	Long bool
+
+	// Aliases are alternative names of the flag.
+	Aliases []string
+
+	// Env is a list of environment variables that the flag can be set from.
+	Env []string
+
+	// Type is the type name of the flag value, such as string, int or duration.
+	// It is empty if the type is unknown.
+	Type string
+
+	// Default is the default value of the flag.
+	// It is empty if the default is the zero value of Type.
+	Default string
+
+	// FlagSet is the name of flag.FlagSet that the flag belongs to.
+	// It is empty if the flag is defined on flag.CommandLine.
+	FlagSet string
+}
+
+// subcommand represents a subcommand that has its own [flag.FlagSet].
+type subcommand struct {
+	Name  string
+	Flags []*Flag
+
+	// Synopsis and Doc are the short and long description of the subcommand.
+	// They are set only if the flag package provides them, like cobra.
+	Synopsis string
+	Doc      string
+}
+
+// groupFlags splits flags into the command's own flags and subcommands' ones.
+// Flags that belong to a FlagSet without name or named cmd are treated as the command's.
+func groupFlags(cmd string, flags []*Flag) ([]*Flag, []*subcommand) {
+	var (
+		own     []*Flag
+		subcmds []*subcommand
+	)
+	for _, flg := range flags {
+		if flg.FlagSet == "" || flg.FlagSet == cmd {
+			own = append(own, flg)
+			continue
+		}
+		i := slices.IndexFunc(subcmds, func(sub *subcommand) bool {
+			return sub.Name == flg.FlagSet
+		})
+		if i < 0 {
+			i = len(subcmds)
+			subcmds = append(subcmds, &subcommand{Name: flg.FlagSet})
+		}
+		subcmds[i].Flags = append(subcmds[i].Flags, flg)
+	}
+	return own, subcmds
+}
+
+// mergeSubcommands attaches flags of subcmds to the documented subcommands cmds
+// that have same name. The rest of subcmds are appended to the result.
+func mergeSubcommands(cmds, subcmds []*subcommand) []*subcommand {
+	for _, c := range cmds {
+		i := slices.IndexFunc(subcmds, func(sub *subcommand) bool {
+			return sub.Name == c.Name
+		})
+		if i >= 0 {
+			c.Flags = append(c.Flags, subcmds[i].Flags...)
+			subcmds = slices.Delete(subcmds, i, i+1)
+		}
+	}
+	return append(cmds, subcmds...)
+}
+
+// sortFiles returns a copy of files sorted by their file names.
+// Analyzers walk the files in the order, thus flags are found in declaration order.
+func sortFiles(fset *token.FileSet, files []*ast.File) []*ast.File {
+	a := slices.Clone(files)
+	slices.SortStableFunc(a, func(x, y *ast.File) int {
+		return strings.Compare(fset.File(x.Pos()).Name(), fset.File(y.Pos()).Name())
+	})
+	return a
+}
+
+// sortFlags sorts flags by their names like [flag.PrintDefaults] does.
+func sortFlags(flags []*Flag) {
+	slices.SortStableFunc(flags, func(a, b *Flag) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// findStdFlags retrieves flags defined with flag package.
+// It recognizes both package-level functions and methods of [flag.FlagSet].
+// Arguments that are not constants are reported to warn unless it is nil.
+func findStdFlags(info *types.Info, fset *token.FileSet, files []*ast.File, warn func(msg string)) []*Flag {
+	var flags []*Flag
+	sets := newFlagSets(stdFlagSet, info, files)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			ast.Inspect(decl, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if flg := flagFunc(info, fset, call, warn); flg != nil {
+					flg.FlagSet = flagSetName(sets, call)
+					flags = append(flags, flg)
+				}
+				return true
+			})
+		}
+	}
+	return flags
+}
+
+var (
+	basicFlags = []string{
+		"Bool",
+		"Duration",
+		"Float64",
+		"Int",
+		"Int64",
+		"String",
+		"Uint",
+		"Uint64",
+	}
+	varFlags  = variants(basicFlags, "Var")
+	funcFlags = []string{"Func", "BoolFunc"}
+)
+
+func variants(flags []string, suffix string) []string {
+	a := make([]string, len(flags))
+	for i, s := range flags {
+		a[i] = s + suffix
+	}
+	return a
+}
+
+// flagFunc returns the flag that call defines.
+func flagFunc(info *types.Info, fset *token.FileSet, call *ast.CallExpr, warn func(msg string)) *Flag {
+	obj := typeutil.Callee(info, call)
+	if !isFlagFunc(obj) {
+		return nil
+	}
+	var (
+		flg    flag.Flag
+		typ    string
+		isBool bool
+	)
+	switch fname := obj.Name(); {
+	default:
+		return nil
+	case fname == "Var" && len(call.Args) == 3:
+		flg = flag.Flag{
+			Name:  argStr(info, fset, call.Args[1], "name", warn),
+			Usage: argStr(info, fset, call.Args[2], "usage", warn),
+		}
+		typ, isBool = valueType(info.TypeOf(call.Args[0]))
+	case fname == "TextVar" && len(call.Args) == 4:
+		flg = flag.Flag{
+			Name:     argStr(info, fset, call.Args[1], "name", warn),
+			Usage:    argStr(info, fset, call.Args[3], "usage", warn),
+			DefValue: textStr(info, call.Args[2]),
+		}
+		typ, _ = valueType(info.TypeOf(call.Args[0]))
+	case slices.Contains(basicFlags, fname) && len(call.Args) == 3:
+		flg = flag.Flag{
+			Name:     argStr(info, fset, call.Args[0], "name", warn),
+			Usage:    argStr(info, fset, call.Args[2], "usage", warn),
+			DefValue: argStr(info, fset, call.Args[1], "default value", warn),
+		}
+		typ = strings.ToLower(fname)
+	case slices.Contains(varFlags, fname) && len(call.Args) == 4:
+		flg = flag.Flag{
+			Name:     argStr(info, fset, call.Args[1], "name", warn),
+			Usage:    argStr(info, fset, call.Args[3], "usage", warn),
+			DefValue: argStr(info, fset, call.Args[2], "default value", warn),
+		}
+		typ = strings.ToLower(strings.TrimSuffix(fname, "Var"))
+	case slices.Contains(funcFlags, fname) && len(call.Args) == 3:
+		flg = flag.Flag{
+			Name:  argStr(info, fset, call.Args[0], "name", warn),
+			Usage: argStr(info, fset, call.Args[1], "usage", warn),
+		}
+		typ = strings.ToLower(strings.TrimSuffix(fname, "Func"))
+	}
+	kind := ValueFlag
+	if typ == "bool" || isBool {
+		kind = BoolFlag
+	}
+	if kind == BoolFlag {
+		flg.Value = boolValue{}
+	}
+	name, usage := flag.UnquoteUsage(&flg)
+	if kind == ValueFlag && typ != "" && !hasVarName(flg.Usage) {
+		if s, ok := stdTypeNames[typ]; ok {
+			name = s
+		} else {
+			name = typ
+		}
+	}
+	return &Flag{
+		Name:        flg.Name,
+		Placeholder: name,
+		Usage:       usage,
+		Kind:        kind,
+		Type:        typ,
+		Default:     nonZero(typ, flg.DefValue),
+	}
+}
+
+// stdTypeNames maps types of the flag package's values to names that [flag.UnquoteUsage] returns.
+var stdTypeNames = map[string]string{
+	"duration": "duration",
+	"float64":  "float",
+	"int":      "int",
+	"int64":    "int",
+	"string":   "string",
+	"uint":     "uint",
+	"uint64":   "uint",
+}
+
+// boolValue is a stand-in for boolean flag.Value that has IsBoolFlag method.
+// With it, [flag.UnquoteUsage] returns an empty name like [flag.PrintDefaults] does.
+type boolValue struct{}
+
+func (boolValue) String() string     { return "" }
+func (boolValue) Set(s string) error { return nil }
+func (boolValue) IsBoolFlag() bool   { return true }
+
+// valueType returns the name of the type t that implements flag.Value or encoding.TextUnmarshaler.
+// It also reports whether t has IsBoolFlag method.
+func valueType(t types.Type) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "IsBoolFlag")
+	_, isBool := obj.(*types.Func)
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return "", isBool
+	}
+	name := named.Obj().Name()
+	if s := strings.TrimSuffix(name, "Value"); s != "" {
+		name = s
+	}
+	return lowerCamel(name), isBool
+}
+
+// switchKind returns the kind of the flag that has the value of typ.
+// The type names are ones of pflag, urfave/cli and kong.
+func switchKind(typ string) FlagKind {
+	switch typ {
+	case "bool", "boolFunc":
+		return BoolFlag
+	case "count":
+		return CountFlag
+	}
+	return ValueFlag
+}
+
+// hasVarName reports whether usage contains a back-quoted name.
+func hasVarName(usage string) bool {
+	_, after, ok := strings.Cut(usage, "`")
+	return ok && strings.Contains(after, "`")
+}
+
+// lowerCamel converts leading upper-case letters of s to lower-case.
+// For example, "StringSlice" is converted to "stringSlice", and "IP" is to "ip".
+func lowerCamel(s string) string {
+	r := []rune(s)
+	for i := range r {
+		if !unicode.IsUpper(r[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+// nonZero returns s unless s is the zero value of typ.
+// Like flag.PrintDefaults, zero values are omitted from the manual.
+func nonZero(typ, s string) string {
+	switch {
+	case typ == "bool":
+		if s == "false" {
+			return ""
+		}
+	case typ == "duration":
+		if s == "0s" {
+			return ""
+		}
+	case slices.Contains(numericTypes, typ):
+		if v, err := strconv.ParseFloat(s, 64); err == nil && v == 0 {
+			return ""
+		}
+	case strings.HasSuffix(typ, "Slice") || strings.HasSuffix(typ, "Array"):
+		if s == "nil" || s == "[]" {
+			return ""
+		}
+	}
+	return s
+}
+
+// numericTypes are the types of numeric flags; pflag's count is also a number.
+var numericTypes = []string{
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64",
+	"float32", "float64", "count",
+}
+
+// flagSetName returns the name of the FlagSet that call is invoked on.
+func flagSetName(sets *flagSets, call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !sets.isFlagSetExpr(sel.X) {
+		return ""
+	}
+	name, _ := sets.NameOf(sel.X)
+	return name
+}
+
+// isFlagFunc reports whether obj is a function of flag package or a method of [flag.FlagSet].
+func isFlagFunc(obj types.Object) bool {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != "flag" {
+		return false
+	}
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		return true
+	}
+	return isFlagSet(recv.Type())
+}
+
+// isFlagSet reports whether t is flag.FlagSet or a pointer to it.
+func isFlagSet(t types.Type) bool {
+	return isNamed(t, "flag", "FlagSet")
+}
+
+// isNamed reports whether t is the named type pkgPath.name or a pointer to it.
+func isNamed(t types.Type, pkgPath, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+// exprStr returns the string representation of expr if it is a constant.
+// It returns an empty string otherwise.
+func exprStr(info *types.Info, expr ast.Expr) string {
+	s, _ := constStr(info, expr)
+	return s
+}
+
+// argStr is like exprStr, but it warns that the argument what is not a constant.
+func argStr(info *types.Info, fset *token.FileSet, expr ast.Expr, what string, warn func(msg string)) string {
+	s, ok := constStr(info, expr)
+	if !ok && expr != nil && warn != nil {
+		warn(fmt.Sprintf("%v: %s of the flag is not a constant; ignored", fset.Position(expr.Pos()), what))
+	}
+	return s
+}
+
+// textStr returns the text that MarshalText of the value expr would return.
+// Because the method can't be run, only the values made in the common ways are recognized:
+// a call that parses a constant string, such as net.ParseIP("127.0.0.1"),
+// and net.IPv4 with constant bytes.
+// It returns an empty string for the others, including constants of the types that have MarshalText.
+func textStr(info *types.Info, expr ast.Expr) string {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(fn.Name(), "Parse") || strings.HasPrefix(fn.Name(), "MustParse"):
+		if len(call.Args) != 1 {
+			return ""
+		}
+		if tv := info.Types[call.Args[0]]; tv.Value != nil && tv.Value.Kind() == constant.String {
+			return constant.StringVal(tv.Value)
+		}
+	case fn.Pkg().Path() == "net" && fn.Name() == "IPv4":
+		a := make([]string, len(call.Args))
+		for i, arg := range call.Args {
+			tv := info.Types[arg]
+			if tv.Value == nil || tv.Value.Kind() != constant.Int {
+				return ""
+			}
+			a[i] = tv.Value.ExactString()
+		}
+		return strings.Join(a, ".")
+	}
+	return ""
+}
+
+// constStr evaluates expr as a constant with the type checker.
+// The result is formatted like the flag package formats the value.
+func constStr(info *types.Info, expr ast.Expr) (string, bool) {
+	if expr == nil {
+		return "", false
+	}
+	tv, ok := info.Types[expr]
+	if !ok {
+		return "", false
+	}
+	if tv.IsNil() {
+		return "", true
+	}
+	if tv.Value == nil {
+		return "", false
+	}
+	v := tv.Value
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v), true
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(v)), true
+	case constant.Int:
+		if isNamed(tv.Type, "time", "Duration") {
+			if n, ok := constant.Int64Val(v); ok {
+				return time.Duration(n).String(), true
+			}
+		}
+		return v.ExactString(), true
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	return v.ExactString(), true
+}
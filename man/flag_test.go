@@ -0,0 +1,327 @@
+package man
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func loadTestPackage(t *testing.T, dir, pattern string) *packages.Package {
+	t.Helper()
+	c := &packages.Config{
+		Dir: dir,
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedImports |
+			packages.NeedDeps |
+			packages.NeedTypes |
+			packages.NeedSyntax |
+			packages.NeedTypesInfo,
+	}
+	pkgs, err := packages.Load(c, pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+		t.Fatalf("failed to load %s", pattern)
+	}
+	return pkgs[0]
+}
+
+func TestFindFlagsFlagSet(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/flagset")
+	var flags []Flag
+	for _, f := range findStdFlags(p.TypesInfo, p.Fset, p.Syntax, nil) {
+		flags = append(flags, *f)
+	}
+	want := []Flag{
+		{Name: "v", Usage: "enable verbose output", Kind: BoolFlag, FlagSet: "flagset", Type: "bool"},
+		{Name: "o", Placeholder: "file", Usage: "write the output to file", FlagSet: "flagset", Type: "string", Default: "a.out"},
+		{Name: "I", Placeholder: "dir", Usage: "add dir to the include path", FlagSet: "flagset", Type: "list"},
+	}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("findStdFlags() = %v; want %v", flags, want)
+	}
+}
+
+func TestGroupFlags(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/subcmd")
+	flags := findStdFlags(p.TypesInfo, p.Fset, p.Syntax, nil)
+	own, subcmds := groupFlags("subcmd", flags)
+	if len(own) != 1 || own[0].Name != "v" {
+		t.Errorf("own flags = %v; want [v]", own)
+	}
+	want := map[string][]string{
+		"build":  {"o"},
+		"deploy": {"host", "n"},
+	}
+	if len(subcmds) != len(want) {
+		t.Fatalf("len(subcmds) = %d; want %d", len(subcmds), len(want))
+	}
+	for _, sub := range subcmds {
+		var names []string
+		for _, flg := range sub.Flags {
+			names = append(names, flg.Name)
+		}
+		if !slices.Equal(names, want[sub.Name]) {
+			t.Errorf("flags of %s = %v; want %v", sub.Name, names, want[sub.Name])
+		}
+	}
+}
+
+func TestFindPFlags(t *testing.T) {
+	p := loadTestPackage(t, "testdata/thirdparty", "./pflag")
+	var warnings []string
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+	var flags []Flag
+	for _, f := range findPFlags(p.TypesInfo, p.Fset, p.Syntax, warn) {
+		flags = append(flags, *f)
+	}
+	want := []Flag{
+		{Name: "verbose", Shorthand: "v", Long: true, Kind: BoolFlag, Usage: "enable verbose output", Type: "bool"},
+		{Name: "output", Shorthand: "o", Long: true, Placeholder: "file", Usage: "write the output to file", Type: "string", Default: "a.out"},
+		{Name: "tags", Long: true, Placeholder: "strings", Usage: "comma-separated list of build tags", Type: "stringSlice"},
+		{Name: "debug", Long: true, Kind: CountFlag, Usage: "increase debug level", Type: "count"},
+		{Name: "addr", Long: true, Placeholder: "ip", Usage: "listen address", Type: "ip"},
+		{Name: "bind", Shorthand: "b", Long: true, Placeholder: "ip", Usage: "bind address", Type: "ip"},
+		{Name: "level", Shorthand: "l", Long: true, Placeholder: "value", Usage: "set the log level"},
+		{Name: "peer", Long: true, Placeholder: "text", Usage: "peer address", Type: "text", Default: "192.0.2.1"},
+	}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("findPFlags() = %v; want %v", flags, want)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("findPFlags() warns %q; want nothing", warnings)
+	}
+}
+
+func TestPFlagType(t *testing.T) {
+	tests := []struct {
+		fname     string
+		shorthand bool
+		want      string
+	}{
+		{"String", false, "string"},
+		{"StringP", true, "string"},
+		{"StringVarP", true, "string"},
+		{"IP", false, "ip"},
+		{"IPP", true, "ip"},
+		{"IPVar", false, "ip"},
+		{"IPVarP", true, "ip"},
+		{"VarPF", true, ""},
+	}
+	for _, tt := range tests {
+		if v := pflagType(tt.fname, tt.shorthand); v != tt.want {
+			t.Errorf("pflagType(%q, %t) = %q; want %q", tt.fname, tt.shorthand, v, tt.want)
+		}
+	}
+}
+
+func TestFindCommands(t *testing.T) {
+	p := loadTestPackage(t, "testdata/thirdparty", "./cobra")
+	cmd := findCobraCommands(p.TypesInfo, p.Fset, p.Syntax, nil)
+	if cmd.Synopsis != "cobra is a test command" || cmd.Doc != "Cobra runs the subcommands." {
+		t.Errorf("root command = {%q %q}; want the description of rootCmd", cmd.Synopsis, cmd.Doc)
+	}
+	own, subcmds := cmd.Flags, cmd.Subcommands
+	if len(own) != 1 || own[0].Name != "verbose" {
+		t.Errorf("own flags = %v; want [verbose]", own)
+	}
+	want := []struct {
+		name     string
+		synopsis string
+		flags    []string
+	}{
+		{"build", "compile packages", []string{"output", "verbose"}},
+		{"deploy", "upload artifacts", []string{"host", "dry-run", "verbose"}},
+	}
+	if len(subcmds) != len(want) {
+		t.Fatalf("len(subcmds) = %d; want %d", len(subcmds), len(want))
+	}
+	for i, sub := range subcmds {
+		var names []string
+		for _, flg := range sub.Flags {
+			names = append(names, flg.Name)
+		}
+		w := want[i]
+		if sub.Name != w.name || sub.Synopsis != w.synopsis || !slices.Equal(names, w.flags) {
+			t.Errorf("subcmds[%d] = {%s %q %v}; want %v", i, sub.Name, sub.Synopsis, names, w)
+		}
+	}
+}
+
+func TestFindUrfaveFlags(t *testing.T) {
+	p := loadTestPackage(t, "testdata/thirdparty", "./urfave")
+	var warnings []string
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+	cmd := findUrfaveFlags(p.TypesInfo, p.Fset, p.Syntax, warn)
+	own, subcmds := cmd.Flags, cmd.Subcommands
+	want := []*Flag{
+		{Name: "config", Long: true, Aliases: []string{"c"}, Env: []string{"URFAVE_CONFIG"}, Placeholder: "FILE", Usage: "load configuration from FILE", Type: "string"},
+		{Name: "verbose", Long: true, Kind: BoolFlag, Usage: "enable verbose output", Type: "bool"},
+	}
+	if !reflect.DeepEqual(own, want) {
+		t.Errorf("own flags = %v; want %v", deref(own), deref(want))
+	}
+	if len(subcmds) != 1 || subcmds[0].Name != "build" || len(subcmds[0].Flags) != 1 {
+		t.Errorf("subcmds = %v; want [build]", subcmds)
+	}
+	if len(warnings) != 1 || !strings.HasSuffix(warnings[0], ": the command has no constant name; ignored") {
+		t.Errorf("findUrfaveFlags() warns %q; want a warning about the command without name", warnings)
+	}
+}
+
+func TestFindKongFlags(t *testing.T) {
+	p := loadTestPackage(t, "testdata/thirdparty", "./kong")
+	cmd := findKongFlags(p.TypesInfo, p.Fset, p.Syntax, nil)
+	own, subcmds := cmd.Flags, cmd.Subcommands
+	want := []*Flag{
+		{Name: "verbose", Shorthand: "v", Long: true, Kind: BoolFlag, Env: []string{"KONG_VERBOSE"}, Usage: "enable verbose output", Type: "bool"},
+		{Name: "http-port", Long: true, Aliases: []string{"port"}, Placeholder: "PORT", Usage: "listen on the port", Type: "int", Default: "8080"},
+	}
+	if !reflect.DeepEqual(own, want) {
+		t.Errorf("own flags = %v; want %v", deref(own), deref(want))
+	}
+	if len(subcmds) != 1 || subcmds[0].Name != "build" || subcmds[0].Synopsis != "compile packages" {
+		t.Fatalf("subcmds = %v; want [build]", subcmds)
+	}
+	if flags := subcmds[0].Flags; len(flags) != 1 || flags[0].Name != "output-file" {
+		t.Errorf("flags of build = %v; want [output-file]", flags)
+	}
+}
+
+func TestDashedString(t *testing.T) {
+	tests := map[string]string{
+		"Verbose":    "verbose",
+		"OutputFile": "output-file",
+		"HTTPPort":   "http-port",
+		"ID":         "id",
+	}
+	for s, want := range tests {
+		if v := dashedString(s); v != want {
+			t.Errorf("dashedString(%q) = %q; want %q", s, v, want)
+		}
+	}
+}
+
+func deref(flags []*Flag) []Flag {
+	a := make([]Flag, len(flags))
+	for i, f := range flags {
+		a[i] = *f
+	}
+	return a
+}
+
+func TestNonZero(t *testing.T) {
+	tests := []struct {
+		typ, s string
+		want   string
+	}{
+		{"bool", "false", ""},
+		{"bool", "true", "true"},
+		{"string", "", ""},
+		{"string", "man", "man"},
+		{"string", "0", "0"},
+		{"string", "[]", "[]"},
+		{"int", "0", ""},
+		{"uint8", "0", ""},
+		{"float64", "0.0", ""},
+		{"float64", "1.5", "1.5"},
+		{"duration", "0s", ""},
+		{"stringSlice", "[]", ""},
+		{"intSlice", "nil", ""},
+	}
+	for _, tt := range tests {
+		if v := nonZero(tt.typ, tt.s); v != tt.want {
+			t.Errorf("nonZero(%q, %q) = %q; want %q", tt.typ, tt.s, v, tt.want)
+		}
+	}
+}
+
+func TestFindFlagsConstant(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/constant")
+	var warnings []string
+	warn := func(msg string) {
+		warnings = append(warnings, msg)
+	}
+	var flags []Flag
+	for _, f := range findStdFlags(p.TypesInfo, p.Fset, p.Syntax, warn) {
+		flags = append(flags, *f)
+	}
+	want := []Flag{
+		{Name: "dir", Placeholder: "dir", Usage: "specify the output directory", Type: "string", Default: "man"},
+		{Name: "mode", Placeholder: "mode", Usage: "set the mode", Type: "string", Default: "fast"},
+		{Name: "timeout", Placeholder: "duration", Usage: "wait for duration", Type: "duration", Default: "5s"},
+		{Name: "ratio", Placeholder: "ratio", Usage: "set the ratio", Type: "float64", Default: "1.5"},
+		{Name: "home", Placeholder: "dir", Usage: "home directory", Type: "string"},
+	}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("findStdFlags() = %v; want %v", flags, want)
+	}
+	if len(warnings) != 1 || !strings.HasSuffix(warnings[0], ": default value of the flag is not a constant; ignored") {
+		t.Errorf("findStdFlags() warns %q; want a warning about the default value of home", warnings)
+	}
+}
+
+func TestFindFlagsValue(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/value")
+	var flags []Flag
+	for _, f := range findStdFlags(p.TypesInfo, p.Fset, p.Syntax, nil) {
+		flags = append(flags, *f)
+	}
+	want := []Flag{
+		{Name: "tags", Placeholder: "stringList", Usage: "comma-separated list of tags", Type: "stringList"},
+		{Name: "trace", Usage: "enable tracing", Kind: BoolFlag, Type: "trace"},
+		{Name: "addr", Placeholder: "ip", Usage: "listen address", Type: "ip", Default: "127.0.0.1"},
+		{Name: "peer", Placeholder: "addr", Usage: "peer address", Type: "addr", Default: "192.0.2.1"},
+		{Name: "level", Placeholder: "level", Usage: "log level", Type: "level"},
+		{Name: "debug", Usage: "enable debug log", Kind: BoolFlag, Type: "bool"},
+		{Name: "n", Placeholder: "int", Usage: "number of workers", Type: "int", Default: "3"},
+		{Name: "define", Placeholder: "name=value", Usage: "define name=value"},
+	}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("findStdFlags() = %v; want %v", flags, want)
+	}
+}
+
+func TestLowerCamel(t *testing.T) {
+	tests := map[string]string{
+		"String":      "string",
+		"StringSlice": "stringSlice",
+		"IP":          "ip",
+		"HTTPPort":    "httpPort",
+		"list":        "list",
+	}
+	for s, want := range tests {
+		if v := lowerCamel(s); v != want {
+			t.Errorf("lowerCamel(%q) = %q; want %q", s, v, want)
+		}
+	}
+}
+
+func TestFindFlagsOrder(t *testing.T) {
+	p := loadTestPackage(t, ".", "./testdata/order")
+	files := sortFiles(p.Fset, p.Syntax)
+	flags := findStdFlags(p.TypesInfo, p.Fset, files, nil)
+	names := func() []string {
+		var a []string
+		for _, f := range flags {
+			a = append(a, f.Name)
+		}
+		return a
+	}
+	if v, want := names(), []string{"b", "a", "z", "m"}; !slices.Equal(v, want) {
+		t.Errorf("findStdFlags() = %v; want %v", v, want)
+	}
+	sortFlags(flags)
+	if v, want := names(), []string{"a", "b", "m", "z"}; !slices.Equal(v, want) {
+		t.Errorf("sortFlags() = %v; want %v", v, want)
+	}
+}
@@ -0,0 +1,171 @@
+package man
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// flagSetKind describes how a flag package creates its FlagSets.
+type flagSetKind struct {
+	// isSet reports whether t is a type that holds flags.
+	isSet func(t types.Type) bool
+
+	// origin returns the name of the FlagSet if x creates a new one.
+	origin func(s *flagSets, x ast.Expr) (string, bool)
+}
+
+var stdFlagSet = &flagSetKind{
+	isSet: isFlagSet,
+	origin: func(s *flagSets, x ast.Expr) (string, bool) {
+		call, ok := x.(*ast.CallExpr)
+		if !ok {
+			return "", false
+		}
+		obj := typeutil.Callee(s.info, call)
+		if isFlagFunc(obj) && obj.Name() == "NewFlagSet" && len(call.Args) == 2 {
+			return exprStr(s.info, call.Args[0]), true
+		}
+		return "", false
+	},
+}
+
+// flagSets tracks names of FlagSets such as the one passed to [flag.NewFlagSet].
+//
+// It binds the name to variables, struct fields and functions
+// that hold or return the FlagSet.
+type flagSets struct {
+	*flagSetKind
+	info  *types.Info
+	names map[types.Object]string
+}
+
+func newFlagSets(kind *flagSetKind, info *types.Info, files []*ast.File) *flagSets {
+	s := &flagSets{
+		flagSetKind: kind,
+		info:        info,
+		names:       make(map[types.Object]string),
+	}
+	for s.scan(files) {
+	}
+	return s
+}
+
+// scan binds names of FlagSets to objects in files.
+// It reports whether any object is newly bound.
+func (s *flagSets) scan(files []*ast.File) bool {
+	changed := false
+	bind := func(obj types.Object, expr ast.Expr) {
+		if obj == nil {
+			return
+		}
+		if _, ok := s.names[obj]; ok {
+			return
+		}
+		if name, ok := s.NameOf(expr); ok {
+			s.names[obj] = name
+			changed = true
+		}
+	}
+	var inspect func(node ast.Node, fn types.Object)
+	inspect = func(node ast.Node, fn types.Object) {
+		ast.Inspect(node, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FuncLit:
+				inspect(n.Body, nil)
+				return false
+			case *ast.AssignStmt:
+				for i, lhs := range n.Lhs {
+					if len(n.Lhs) == len(n.Rhs) {
+						bind(s.objectOf(lhs), n.Rhs[i])
+					} else if len(n.Rhs) == 1 && s.isFlagSetExpr(lhs) {
+						bind(s.objectOf(lhs), n.Rhs[0])
+					}
+				}
+			case *ast.ValueSpec:
+				for i, name := range n.Names {
+					if len(n.Names) == len(n.Values) {
+						bind(s.info.Defs[name], n.Values[i])
+					} else if len(n.Values) == 1 && s.isFlagSetExpr(name) {
+						bind(s.info.Defs[name], n.Values[0])
+					}
+				}
+			case *ast.CompositeLit:
+				for _, elt := range n.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					if key, ok := kv.Key.(*ast.Ident); ok {
+						bind(s.info.Uses[key], kv.Value)
+					}
+				}
+			case *ast.ReturnStmt:
+				for _, r := range n.Results {
+					if s.isFlagSetExpr(r) {
+						bind(fn, r)
+					}
+				}
+			}
+			return true
+		})
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			var fn types.Object
+			if d, ok := decl.(*ast.FuncDecl); ok {
+				fn = s.info.Defs[d.Name]
+			}
+			inspect(decl, fn)
+		}
+	}
+	return changed
+}
+
+// NameOf returns the name of the FlagSet that expr evaluates to.
+// It reports false if expr is not known as a FlagSet.
+func (s *flagSets) NameOf(expr ast.Expr) (string, bool) {
+	expr = ast.Unparen(expr)
+	if name, ok := s.origin(s, expr); ok {
+		return name, true
+	}
+	switch x := expr.(type) {
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			return s.NameOf(x.X)
+		}
+	case *ast.StarExpr:
+		return s.NameOf(x.X)
+	case *ast.CallExpr:
+		name, ok := s.names[typeutil.Callee(s.info, x)]
+		return name, ok
+	case *ast.Ident, *ast.SelectorExpr:
+		name, ok := s.names[s.objectOf(x)]
+		return name, ok
+	}
+	return "", false
+}
+
+// objectOf returns the variable or the field that expr denotes.
+func (s *flagSets) objectOf(expr ast.Expr) types.Object {
+	switch x := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		if obj := s.info.Defs[x]; obj != nil {
+			return obj
+		}
+		return s.info.Uses[x]
+	case *ast.SelectorExpr:
+		if sel := s.info.Selections[x]; sel != nil {
+			return sel.Obj()
+		}
+		return s.info.Uses[x.Sel]
+	}
+	return nil
+}
+
+func (s *flagSets) isFlagSetExpr(expr ast.Expr) bool {
+	t := s.info.TypeOf(expr)
+	return t != nil && s.isSet(t)
+}
@@ -0,0 +1,599 @@
+// Package man generates manuals from the documents of Go packages.
+package man
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"go/doc"
+	"go/doc/comment"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/lufia/godoc2man/internal/diff"
+	"github.com/lufia/godoc2man/internal/language"
+)
+
+// Config is the configuration of Generate.
+// The zero value generates man(7) manuals without options sections.
+type Config struct {
+	// Lang is the language code that is used for GoDoc document.
+	Lang string
+
+	// Flag is the flag package of commands; it is std, pflag, cobra, urfave, kong or none.
+	// Options sections are generated from sources with static analysis.
+	Flag string
+
+	// Tags is the list of build tags.
+	Tags []string
+
+	// Sort is the key to sort options by; it is name or source.
+	// The default is source, declaration order.
+	Sort string
+
+	// Format is the output format; it is man, mdoc, markdown, html or json.
+	// The default is man.
+	Format string
+
+	// Completions is the list of the shells to generate completion scripts for commands;
+	// shell is bash, zsh or fish.
+	Completions []string
+
+	// Dir is the output directory.
+	// If Dir is not empty, Generate writes the files into Dir as well as returns them.
+	Dir string
+
+	// Source is the source of the manuals shown in the footer, such as "myproj 1.4.0".
+	// The default is the module path and its version of each package.
+	Source string
+
+	// Manual is the title of the manual shown in the header, such as "MyProj Manual".
+	Manual string
+
+	// Version overrides the version of the modules, for example, to the one stamped via -ldflags.
+	// The version is shown in the footer.
+	Version string
+
+	// VersionSection appends VERSION section to the manuals if the version is known.
+	VersionSection bool
+
+	// FailFast stops Generate at the first package that fails.
+	// Otherwise Generate continues to the remaining packages.
+	FailFast bool
+
+	// Warn is called with the problems that don't stop Generate,
+	// such as flags whose names are not constants.
+	// If Warn is nil, the problems are discarded.
+	Warn func(msg string)
+}
+
+// ConfigError reports that a field of Config has an unsupported value.
+type ConfigError struct {
+	// Field is the name of the field, such as "Format".
+	Field string
+	Value string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("man: unsupported %s %q", e.Field, e.Value)
+}
+
+// PackageError is an error that occurred in generating the manuals of a package.
+type PackageError struct {
+	Path string
+	Err  error
+}
+
+func (e *PackageError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *PackageError) Unwrap() error {
+	return e.Err
+}
+
+// File is a file generated by Generate.
+type File struct {
+	// Path is the slash-separated path relative to the output directory, such as "man1/godoc2man.1".
+	Path string
+
+	// Package is the import path of the package that the file is generated from.
+	// It is empty if the file is not generated from a package, such as index.html.
+	Package string
+
+	// Page is the manual that the file represents.
+	// It is nil if the file is not a manual, such as index.html or completion scripts.
+	Page *ManPage
+
+	Data []byte
+}
+
+// WriteTo writes the contents of f to w.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(f.Data)
+	return int64(n), err
+}
+
+// Generate generates manuals of the packages named by patterns.
+// If patterns is empty, Generate generates the package in the current directory.
+//
+// The date of the manuals is taken from SOURCE_DATE_EPOCH environment variable,
+// the last commit that changes each package, or the time of the module version in this order.
+//
+// If c has an unsupported value, Generate returns [*ConfigError] without generating any manuals.
+// Errors of each package are reported as [*PackageError] joined into the returned error.
+// Even if some packages fail, Generate returns and writes the files of the other packages
+// unless c.FailFast is set.
+func Generate(ctx context.Context, c *Config, patterns ...string) ([]*File, error) {
+	if c == nil {
+		c = &Config{}
+	}
+	g := &generator{c: c}
+	format, ok := outputFormats[g.format()]
+	if !ok {
+		return nil, &ConfigError{Field: "Format", Value: g.format()}
+	}
+	g.outputFormat = format
+	for _, shell := range c.Completions {
+		if _, ok := completionFiles[shell]; !ok {
+			return nil, &ConfigError{Field: "Completions", Value: shell}
+		}
+	}
+	if !slices.Contains(flagPackages, cmp.Or(c.Flag, "none")) {
+		return nil, &ConfigError{Field: "Flag", Value: c.Flag}
+	}
+	if !slices.Contains(sortKeys, cmp.Or(c.Sort, "source")) {
+		return nil, &ConfigError{Field: "Sort", Value: c.Sort}
+	}
+
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	errs, err := g.run(ctx, patterns)
+	if err != nil {
+		return nil, err
+	}
+	if g.format() == "html" && len(g.files) > 0 {
+		if err := g.writeIndex(); err != nil {
+			return nil, err
+		}
+	}
+	if c.Dir != "" {
+		if err := WriteFiles(c.Dir, g.files); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return g.files, errors.Join(errs...)
+}
+
+// WriteFiles writes files into dir.
+// It continues to write the remaining files even if some of them fail.
+func WriteFiles(dir string, files []*File) error {
+	var errs []error
+	for _, f := range files {
+		file := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := WriteFile(file, f.Data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Diff compares files with the ones in dir, then returns the unified diff of files that are out of date.
+// Files that don't exist in dir are compared as empty files.
+// It returns nil if all files are up to date.
+func Diff(dir string, files []*File) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, f := range files {
+		file := filepath.Join(dir, filepath.FromSlash(f.Path))
+		data, err := os.ReadFile(file)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		name := filepath.ToSlash(file)
+		buf.Write(diff.Diff(path.Join("a", name), data, path.Join("b", name), f.Data))
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes data into file atomically.
+// It writes data into a temporary file in the same directory, then renames it to file,
+// so that file is never truncated even if writing fails.
+func WriteFile(file string, data []byte) (err error) {
+	dir := filepath.Dir(file)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Chmod(0644); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
+
+// generator holds the state of a Generate call.
+type generator struct {
+	c            *Config
+	outputFormat *outputFormat
+	files        []*File
+}
+
+func (g *generator) format() string {
+	return cmp.Or(g.c.Format, "man")
+}
+
+// run generates the packages named by patterns.
+// It returns errors of each package as errs, and returns err if packages can't be loaded at all.
+func (g *generator) run(ctx context.Context, patterns []string) (errs []error, err error) {
+	c := &packages.Config{
+		Context: ctx,
+		Mode: packages.NeedName |
+			packages.NeedFiles |
+			packages.NeedModule |
+			packages.NeedImports |
+			packages.NeedDeps |
+			packages.NeedTypes |
+			packages.NeedSyntax |
+			packages.NeedTypesInfo,
+	}
+	if len(g.c.Tags) > 0 {
+		c.BuildFlags = append(c.BuildFlags, "-tags", strings.Join(g.c.Tags, ","))
+	}
+	pkgs, err := packages.Load(c, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		files, err := g.generate(ctx, pkg)
+		if err != nil {
+			errs = append(errs, &PackageError{Path: pkg.ID, Err: err})
+			if g.c.FailFast {
+				break
+			}
+			continue
+		}
+		for _, f := range files {
+			f.Package = pkg.ID
+		}
+		g.files = append(g.files, files...)
+	}
+	return errs, nil
+}
+
+// generate returns the files of pkg.
+func (g *generator) generate(ctx context.Context, pkg *packages.Package) ([]*File, error) {
+	if err := packageErrors(pkg); err != nil {
+		return nil, err
+	}
+	date, err := sourceDate(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	var modPath, version string
+	if pkg.Module != nil {
+		modPath, version = pkg.Module.Path, pkg.Module.Version
+	}
+	version = cmp.Or(g.c.Version, version)
+	stamp := func(m *ManPage) *ManPage {
+		m.Date = date
+		m.Source = cmp.Or(g.c.Source, strings.TrimSpace(modPath+" "+version))
+		m.Manual = g.c.Manual
+		m.Version = version
+		if g.c.VersionSection {
+			m.addVersion(modPath)
+		}
+		return m
+	}
+
+	// doc.NewFromFiles drops unexported declarations from the files,
+	// so flags have to be retrieved before it.
+	cmd := &commandInfo{}
+	if pkg.Name == "main" {
+		files := sortFiles(pkg.Fset, pkg.Syntax)
+		cmd = g.retrieveFlags(pkg, path.Base(pkg.ID))
+		env := findEnv(pkg.TypesInfo, pkg.Fset, files)
+		cmd.Env = mergeEnv(env, flagEnv(cmd.Flags, cmd.Subcommands))
+		cmd.ExitStatus = findExitStatus(pkg.TypesInfo, pkg.Fset, files)
+	}
+	p, err := doc.NewFromFiles(pkg.Fset, pkg.Syntax, pkg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("parsing documents: %w", err)
+	}
+
+	s, err := language.String(g.c.Lang, p.Doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform to language '%s': %w", g.c.Lang, err)
+	}
+	var parser comment.Parser
+	doc := parser.Parse(s)
+	section := manualSection(pkg.Name)
+	if pkg.Name != "main" {
+		m, err := newLibraryPage(pkg.Fset, p, doc, pkg.ID, section)
+		if err != nil {
+			return nil, err
+		}
+		f, err := g.renderManual(pkg.ID, stamp(m))
+		if err != nil {
+			return nil, err
+		}
+		return []*File{f}, nil
+	}
+	f, err := g.renderManual(pkg.ID, stamp(newCommandPage(p, doc, pkg.ID, section, cmd)))
+	if err != nil {
+		return nil, err
+	}
+	files := []*File{f}
+	scripts, err := g.renderCompletions(path.Base(pkg.ID), cmd)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, scripts...)
+	for _, sub := range cmd.Subcommands {
+		pkgPath := pkg.ID + "-" + sub.Name
+		f, err := g.renderManual(pkgPath, stamp(newSubcommandPage(p, doc, pkgPath, section, sub)))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// sourceDate returns the date of the last change of pkg.
+// It returns zero time if the date is unknown.
+func sourceDate(ctx context.Context, pkg *packages.Package) (time.Time, error) {
+	if s := os.Getenv("SOURCE_DATE_EPOCH"); s != "" {
+		sec, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("SOURCE_DATE_EPOCH: %w", err)
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	if len(pkg.GoFiles) > 0 {
+		if t, ok := commitDate(ctx, pkg.GoFiles); ok {
+			return t, nil
+		}
+	}
+	if pkg.Module != nil && pkg.Module.Time != nil {
+		return pkg.Module.Time.UTC(), nil
+	}
+	return time.Time{}, nil
+}
+
+// commitDate returns the date of the last commit that changes files.
+// Only the source files are considered because the generated files may be placed in the same directory.
+// It reports false if files are not managed by git, or have not been committed.
+func commitDate(ctx context.Context, files []string) (time.Time, bool) {
+	args := append([]string{"log", "-1", "--format=%ct", "--"}, files...)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = filepath.Dir(files[0])
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0).UTC(), true
+}
+
+// packageErrors returns the errors of pkg and its dependencies that occurred in loading.
+func packageErrors(pkg *packages.Package) error {
+	var errs []error
+	packages.Visit([]*packages.Package{pkg}, nil, func(p *packages.Package) {
+		for _, err := range p.Errors {
+			errs = append(errs, err)
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// pagePrinter is the interface that writes manuals in a format.
+type pagePrinter interface {
+	Page(m *ManPage)
+	Err() error
+}
+
+var (
+	_ pagePrinter = (*manPrinter)(nil)
+	_ pagePrinter = (*mdocPrinter)(nil)
+	_ pagePrinter = (*markdownPrinter)(nil)
+	_ pagePrinter = (*htmlPrinter)(nil)
+	_ pagePrinter = (*jsonPrinter)(nil)
+)
+
+// outputFormat is a format of manuals.
+type outputFormat struct {
+	// ext is the extension of the files; empty means the manual section.
+	ext        string
+	newPrinter func(w io.Writer) pagePrinter
+}
+
+var outputFormats = map[string]*outputFormat{
+	"man": {
+		newPrinter: func(w io.Writer) pagePrinter { return newManPrinter(w) },
+	},
+	"mdoc": {
+		newPrinter: func(w io.Writer) pagePrinter { return newMdocPrinter(w) },
+	},
+	"markdown": {
+		ext:        ".md",
+		newPrinter: func(w io.Writer) pagePrinter { return newMarkdownPrinter(w) },
+	},
+	"html": {
+		ext:        ".html",
+		newPrinter: func(w io.Writer) pagePrinter { return newHTMLPrinter(w) },
+	},
+	"json": {
+		ext:        ".json",
+		newPrinter: func(w io.Writer) pagePrinter { return newJSONPrinter(w) },
+	},
+}
+
+// renderManual renders m into the manual file for pkgPath.
+func (g *generator) renderManual(pkgPath string, m *ManPage) (*File, error) {
+	ext := g.outputFormat.ext
+	if ext == "" {
+		ext = "." + m.Section
+	}
+	var buf bytes.Buffer
+	printer := g.outputFormat.newPrinter(&buf)
+	printer.Page(m)
+	if err := printer.Err(); err != nil {
+		return nil, err
+	}
+	return &File{
+		Path: path.Join("man"+m.Section, manualFileName(pkgPath, ext)),
+		Page: m,
+		Data: buf.Bytes(),
+	}, nil
+}
+
+// renderCompletions renders the completion scripts for the command name into the completions directory.
+func (g *generator) renderCompletions(name string, cmd *commandInfo) ([]*File, error) {
+	var files []*File
+	for _, shell := range g.c.Completions {
+		var buf bytes.Buffer
+		if err := writeCompletion(&buf, shell, name, cmd.Flags, cmd.Subcommands); err != nil {
+			return nil, err
+		}
+		files = append(files, &File{
+			Path: path.Join("completions", completionFiles[shell](name)),
+			Data: buf.Bytes(),
+		})
+	}
+	return files, nil
+}
+
+// writeIndex renders index.html that links to the manuals generated so far.
+func (g *generator) writeIndex() error {
+	var entries []*indexEntry
+	for _, f := range g.files {
+		if f.Page == nil {
+			continue
+		}
+		entries = append(entries, &indexEntry{
+			Name:     f.Page.Name,
+			Section:  f.Page.Section,
+			Synopsis: f.Page.Description,
+			File:     f.Path,
+		})
+	}
+	slices.SortStableFunc(entries, func(a, b *indexEntry) int {
+		return cmp.Or(strings.Compare(a.Section, b.Section), strings.Compare(a.Name, b.Name))
+	})
+	var buf bytes.Buffer
+	if err := writeHTMLIndex(&buf, entries); err != nil {
+		return err
+	}
+	g.files = append(g.files, &File{Path: "index.html", Data: buf.Bytes()})
+	return nil
+}
+
+func manualSection(name string) string {
+	if name == "main" {
+		return "1"
+	}
+	return "3"
+}
+
+// manualFileName returns the file name of the manual for pkgPath.
+func manualFileName(pkgPath, ext string) string {
+	return strings.ReplaceAll(pkgPath, "/", "-") + ext
+}
+
+// isPathElem reports whether name is a single element of file paths that has no spaces.
+func isPathElem(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsFunc(name, func(c rune) bool {
+		return c == '/' || c == '\\' || unicode.IsSpace(c) || !unicode.IsPrint(c)
+	})
+}
+
+// flagPackages is the list of the packages that Config.Flag accepts.
+var flagPackages = []string{"std", "pflag", "cobra", "urfave", "kong", "none"}
+
+// sortKeys is the list of the keys that Config.Sort accepts.
+var sortKeys = []string{"source", "name"}
+
+func (g *generator) retrieveFlags(p *packages.Package, cmd string) *commandInfo {
+	c := g.findFlags(p, cmd)
+	// Names of subcommands come from the sources, and they make file names of the manuals.
+	c.Subcommands = slices.DeleteFunc(c.Subcommands, func(sub *subcommand) bool {
+		if isPathElem(sub.Name) {
+			return false
+		}
+		if g.c.Warn != nil {
+			g.c.Warn(fmt.Sprintf("%s: subcommand %q can't be a file name; ignored", p.ID, sub.Name))
+		}
+		return true
+	})
+	switch cmp.Or(g.c.Sort, "source") {
+	case "source":
+	case "name":
+		sortFlags(c.Flags)
+		for _, sub := range c.Subcommands {
+			sortFlags(sub.Flags)
+		}
+		slices.SortStableFunc(c.Subcommands, func(a, b *subcommand) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+	return c
+}
+
+// findFlags returns the command that has flags in declaration order.
+func (g *generator) findFlags(p *packages.Package, cmd string) *commandInfo {
+	files := sortFiles(p.Fset, p.Syntax)
+	var flags []*Flag
+	switch cmp.Or(g.c.Flag, "none") {
+	case "none":
+	case "std":
+		flags = findStdFlags(p.TypesInfo, p.Fset, files, g.c.Warn)
+	case "pflag":
+		flags = findPFlags(p.TypesInfo, p.Fset, files, g.c.Warn)
+	case "cobra":
+		return findCobraCommands(p.TypesInfo, p.Fset, files, g.c.Warn)
+	case "urfave":
+		return findUrfaveFlags(p.TypesInfo, p.Fset, files, g.c.Warn)
+	case "kong":
+		return findKongFlags(p.TypesInfo, p.Fset, files, g.c.Warn)
+	}
+	c := &commandInfo{}
+	c.Flags, c.Subcommands = groupFlags(cmd, flags)
+	return c
+}
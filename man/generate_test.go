@@ -0,0 +1,215 @@
+package man
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestGenerate(t *testing.T) {
+	c := &Config{
+		Flag:        "std",
+		Completions: []string{"zsh"},
+	}
+	files, err := Generate(context.Background(), c, "./testdata/subcmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	want := []string{
+		"man1/github.com-lufia-godoc2man-man-testdata-subcmd.1",
+		"completions/_subcmd",
+		"man1/github.com-lufia-godoc2man-man-testdata-subcmd-build.1",
+		"man1/github.com-lufia-godoc2man-man-testdata-subcmd-deploy.1",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Generate(...) = %q; want %q", paths, want)
+	}
+	if files[0].Page == nil || files[0].Page.Name != "subcmd" {
+		t.Errorf("Page = %+v; want the manual of subcmd", files[0].Page)
+	}
+	for _, f := range files {
+		if want := "github.com/lufia/godoc2man/man/testdata/subcmd"; f.Package != want {
+			t.Errorf("Package of %s = %q; want %q", f.Path, f.Package, want)
+		}
+	}
+}
+
+func TestGenerateInvalidSubcommand(t *testing.T) {
+	var warnings []string
+	c := &Config{
+		Flag: "std",
+		Warn: func(msg string) {
+			warnings = append(warnings, msg)
+		},
+	}
+	files, err := Generate(context.Background(), c, "./testdata/badsubcmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	want := []string{
+		"man1/github.com-lufia-godoc2man-man-testdata-badsubcmd.1",
+		"man1/github.com-lufia-godoc2man-man-testdata-badsubcmd-run.1",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Generate(...) = %q; want %q", paths, want)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("Generate(...) warns %q; want 2 warnings", warnings)
+	}
+}
+
+func TestIsPathElem(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"build", true},
+		{"build-all", true},
+		{"", false},
+		{"..", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{"two words", false},
+	}
+	for _, tt := range tests {
+		if v := isPathElem(tt.name); v != tt.want {
+			t.Errorf("isPathElem(%q) = %t; want %t", tt.name, v, tt.want)
+		}
+	}
+}
+
+func TestGenerateVersionSection(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		c := &Config{Version: "v1.4.0", VersionSection: enabled}
+		files, err := Generate(context.Background(), c, "./testdata/oneline")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := files[0].Page
+		if m.Version != "v1.4.0" {
+			t.Errorf("Version = %q; want %q", m.Version, "v1.4.0")
+		}
+		if s := findSection(m.Sections, "VERSION"); (s != nil) != enabled {
+			t.Errorf("VersionSection = %t: VERSION section = %v", enabled, s)
+		}
+	}
+}
+
+func TestGenerateUnsupportedConfig(t *testing.T) {
+	tests := []struct {
+		c    *Config
+		want ConfigError
+	}{
+		{&Config{Format: "pdf"}, ConfigError{Field: "Format", Value: "pdf"}},
+		{&Config{Flag: "getopt"}, ConfigError{Field: "Flag", Value: "getopt"}},
+		{&Config{Sort: "type"}, ConfigError{Field: "Sort", Value: "type"}},
+		{&Config{Completions: []string{"bash", "ksh"}}, ConfigError{Field: "Completions", Value: "ksh"}},
+	}
+	for _, tt := range tests {
+		_, err := Generate(context.Background(), tt.c, "./testdata/subcmd")
+		var e *ConfigError
+		if !errors.As(err, &e) || *e != tt.want {
+			t.Errorf("Generate(%+v) = %v; want %v", tt.c, err, &tt.want)
+		}
+	}
+}
+
+func TestGenerateContinue(t *testing.T) {
+	tests := []struct {
+		failfast bool
+		n        int
+	}{
+		{false, 1},
+		{true, 0},
+	}
+	for _, tt := range tests {
+		c := &Config{FailFast: tt.failfast}
+		files, err := Generate(context.Background(), c, "./testdata/nonexistent", "./testdata/exit")
+		var e *PackageError
+		if !errors.As(err, &e) || e.Path != "./testdata/nonexistent" {
+			t.Errorf("Generate(FailFast=%t) = %v; want an error of ./testdata/nonexistent", tt.failfast, err)
+		}
+		if len(files) != tt.n {
+			t.Errorf("Generate(FailFast=%t) returns %d files; want %d", tt.failfast, len(files), tt.n)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.1")
+	if err := WriteFile(file, []byte("old\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(file, []byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != "new\n" {
+		t.Errorf("WriteFile: content = %q; want %q", s, "new\n")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("WriteFile leaves temporary files: %v", entries)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	files := []*File{
+		{Path: "man1/a.1", Data: []byte(".TH a 1\n")},
+	}
+	if err := WriteFiles(dir, files); err != nil {
+		t.Fatal(err)
+	}
+	d, err := Diff(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != nil {
+		t.Errorf("Diff(up to date) = %q; want nil", d)
+	}
+
+	files = append(files, &File{Path: "man1/b.1", Data: []byte(".TH b 1\n")})
+	d, err = Diff(dir, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.ToSlash(filepath.Join(dir, "man1/b.1"))
+	want := "--- " + path.Join("a", name) + "\n+++ " + path.Join("b", name) + "\n@@ -0,0 +1 @@\n+.TH b 1\n"
+	if s := string(d); s != want {
+		t.Errorf("Diff(missing file) = %q; want %q", s, want)
+	}
+}
+
+func TestSourceDate(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	d, err := sourceDate(context.Background(), &packages.Package{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC); !d.Equal(want) {
+		t.Errorf("sourceDate() = %v; want %v", d, want)
+	}
+}
@@ -0,0 +1,307 @@
+package man
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/doc/comment"
+	"html"
+	"io"
+	"strings"
+)
+
+// stylesheet is the default stylesheet embedded in each HTML document.
+//
+//go:embed style.css
+var stylesheet string
+
+// htmlPrinter writes manuals as standalone HTML documents.
+type htmlPrinter struct {
+	w   io.Writer
+	err error
+}
+
+func newHTMLPrinter(w io.Writer) *htmlPrinter {
+	return &htmlPrinter{w, nil}
+}
+
+func (p *htmlPrinter) Err() error {
+	return p.err
+}
+
+func (p *htmlPrinter) Write(data []byte) (n int, err error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	n, p.err = p.w.Write(data)
+	return n, p.err
+}
+
+func (p *htmlPrinter) Page(m *ManPage) {
+	title := fmt.Sprintf("%s(%s)", m.Name, m.Section)
+	writeHTMLBegin(p, title)
+	fmt.Fprintf(p, "<h1>%s</h1>\n", html.EscapeString(title))
+	fmt.Fprintln(p, `<h2 id="name">NAME</h2>`)
+	fmt.Fprintf(p, "<p>%s &mdash; %s</p>\n", html.EscapeString(m.Name), html.EscapeString(m.Description))
+	p.writeSynopsis(m.Synopsis, len(m.Options) > 0)
+	p.writeOptions(m.Options)
+	for _, s := range m.Sections {
+		title := html.EscapeString(strings.ToUpper(sectionTitle(s)))
+		if s.Authored {
+			fmt.Fprintf(p, "<h2>%s</h2>\n", title)
+		} else {
+			fmt.Fprintf(p, `<h2 id="%s">%s</h2>`+"\n", htmlID(sectionTitle(s)), title)
+		}
+		p.writeSection(s, 3)
+	}
+	if len(m.SeeAlso) > 0 {
+		fmt.Fprintln(p, `<h2 id="see-also">SEE ALSO</h2>`)
+		a := make([]string, len(m.SeeAlso))
+		for i, ref := range m.SeeAlso {
+			a[i] = htmlRef(ref)
+		}
+		fmt.Fprintf(p, "<p>%s</p>\n", strings.Join(a, ", "))
+	}
+	if len(m.Bugs) > 0 {
+		fmt.Fprintln(p, `<h2 id="bugs">BUGS</h2>`)
+		for _, s := range m.Bugs {
+			fmt.Fprintf(p, "<p>%s</p>\n", html.EscapeString(strings.TrimSpace(s)))
+		}
+	}
+	if a := m.footer(); len(a) > 0 {
+		fmt.Fprintf(p, "<footer>%s</footer>\n", html.EscapeString(strings.Join(a, " — ")))
+	}
+	writeHTMLEnd(p)
+}
+
+// htmlID returns the id attribute for the heading s.
+func htmlID(s string) string {
+	return html.EscapeString(strings.ReplaceAll(strings.ToLower(s), " ", "-"))
+}
+
+// htmlRef returns the link to the page that ref refers to.
+// The page is expected in the same directory.
+func htmlRef(ref *Reference) string {
+	file := manualFileName(ref.Path, ".html")
+	return fmt.Sprintf(`<a href="%s">%s(%s)</a>`, html.EscapeString(file), html.EscapeString(ref.Name), ref.Section)
+}
+
+func (p *htmlPrinter) writeSynopsis(s *Synopsis, hasOptions bool) {
+	if s == nil {
+		return
+	}
+	fmt.Fprintln(p, `<h2 id="synopsis">SYNOPSIS</h2>`)
+	if s.Import == "" {
+		fmt.Fprintf(p, "<p><b>%s</b>", html.EscapeString(s.Command))
+		if hasOptions {
+			fmt.Fprint(p, " [<i>options</i>]")
+		}
+		for _, arg := range s.Args {
+			fmt.Fprintf(p, " <i>%s</i>", html.EscapeString(arg))
+		}
+		fmt.Fprintln(p, "</p>")
+		return
+	}
+	fmt.Fprintf(p, "<pre><code>import %s\n", html.EscapeString(fmt.Sprintf("%q", s.Import)))
+	for _, c := range s.Consts {
+		fmt.Fprintf(p, "\n%s\n", html.EscapeString(c))
+	}
+	for _, v := range s.Vars {
+		fmt.Fprintf(p, "\n%s\n", html.EscapeString(v))
+	}
+	for _, t := range s.Types {
+		fmt.Fprintf(p, "\n%s\n", html.EscapeString(t.Decl))
+		for _, c := range t.Consts {
+			fmt.Fprintf(p, "%s\n", html.EscapeString(c))
+		}
+		for _, f := range t.Funcs {
+			fmt.Fprintf(p, "%s\n", html.EscapeString(f))
+		}
+	}
+	if len(s.Funcs) > 0 {
+		fmt.Fprintln(p, "")
+	}
+	for _, f := range s.Funcs {
+		fmt.Fprintf(p, "%s\n", html.EscapeString(f))
+	}
+	fmt.Fprintln(p, "</code></pre>")
+}
+
+func (p *htmlPrinter) writeOptions(flags []*Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintln(p, `<h2 id="options">OPTIONS</h2>`)
+	fmt.Fprintln(p, "<dl>")
+	for _, flg := range flags {
+		names := []string{"-" + flg.Name}
+		if flg.Long {
+			names = optionNames(flg)
+		}
+		a := make([]string, len(names))
+		for i, name := range names {
+			a[i] = "<code>" + html.EscapeString(name) + "</code>"
+		}
+		fmt.Fprintf(p, "<dt>%s", strings.Join(a, ", "))
+		if flg.Placeholder != "" {
+			fmt.Fprintf(p, "=<var>%s</var>", html.EscapeString(flg.Placeholder))
+		}
+		fmt.Fprintf(p, "</dt>\n<dd>%s", html.EscapeString(flg.Usage+defaultText(flg)))
+		if len(flg.Env) > 0 {
+			a := make([]string, len(flg.Env))
+			for i, s := range flg.Env {
+				a[i] = "<code>$" + html.EscapeString(s) + "</code>"
+			}
+			fmt.Fprintf(p, " [%s]", strings.Join(a, ", "))
+		}
+		fmt.Fprintln(p, "</dd>")
+	}
+	fmt.Fprintln(p, "</dl>")
+}
+
+// writeSection writes the body of s; level is the heading level of subsections.
+func (p *htmlPrinter) writeSection(s *Section, level int) {
+	p.writeContent(s.Content)
+	if len(s.Definitions) > 0 {
+		fmt.Fprintln(p, "<dl>")
+		for _, d := range s.Definitions {
+			switch d.Kind {
+			case CommandDefinition, ExitStatusDefinition:
+				fmt.Fprintf(p, "<dt>%s</dt>\n", html.EscapeString(d.Term))
+			default:
+				fmt.Fprintf(p, "<dt><code>%s</code></dt>\n", html.EscapeString(d.Term))
+			}
+			fmt.Fprintf(p, "<dd>%s", html.EscapeString(d.Doc))
+			if d.Ref != nil {
+				if d.Doc != "" {
+					fmt.Fprint(p, " ")
+				}
+				fmt.Fprintf(p, "See %s.", htmlRef(d.Ref))
+			}
+			fmt.Fprintln(p, "</dd>")
+		}
+		fmt.Fprintln(p, "</dl>")
+	}
+	for _, sym := range s.Symbols {
+		content := sym.Content
+		if sym.Named {
+			name := sym.Name
+			if sym.Kind == FuncSymbol {
+				name += "()"
+			}
+			lead := "<b>" + html.EscapeString(name) + "</b>"
+			if sym.Article != "" {
+				lead = sym.Article + " " + lead
+			}
+			// The first paragraph continues the sentence that begins with the name.
+			if len(content) > 0 {
+				if c, ok := content[0].(*comment.Paragraph); ok {
+					fmt.Fprintf(p, "<p>%s %s</p>\n", lead, htmlText(c.Text))
+					content = content[1:]
+				}
+			}
+		}
+		p.writeContent(content)
+	}
+	for _, sub := range s.Subsections {
+		fmt.Fprintf(p, "<h%d>%s</h%d>\n", level, html.EscapeString(sub.Name), level)
+		p.writeSection(sub, level+1)
+	}
+}
+
+func (p *htmlPrinter) writeContent(content []comment.Block) {
+	for _, c := range content {
+		switch c := c.(type) {
+		case *comment.Heading:
+			fmt.Fprintf(p, "<h2>%s</h2>\n", html.EscapeString(strings.ToUpper(plainText(c.Text))))
+		case *comment.Paragraph:
+			fmt.Fprintf(p, "<p>%s</p>\n", htmlText(c.Text))
+		case *comment.Code:
+			fmt.Fprintf(p, "<pre>%s</pre>\n", html.EscapeString(c.Text))
+		case *comment.List:
+			tag := "ul"
+			if c.Items[0].Number != "" {
+				tag = "ol"
+			}
+			fmt.Fprintf(p, "<%s>\n", tag)
+			for _, item := range c.Items {
+				fmt.Fprint(p, "<li>")
+				if len(item.Content) == 1 {
+					// A single paragraph is written without <p> like go/doc/comment.Printer.HTML.
+					if para, ok := item.Content[0].(*comment.Paragraph); ok {
+						fmt.Fprintf(p, "%s</li>\n", htmlText(para.Text))
+						continue
+					}
+				}
+				fmt.Fprintln(p, "")
+				p.writeContent(item.Content)
+				fmt.Fprintln(p, "</li>")
+			}
+			fmt.Fprintf(p, "</%s>\n", tag)
+		}
+	}
+}
+
+// htmlText returns the HTML representation of a.
+func htmlText(a []comment.Text) string {
+	var b strings.Builder
+	for _, t := range a {
+		switch t := t.(type) {
+		case comment.Plain:
+			b.WriteString(html.EscapeString(string(t)))
+		case comment.Italic:
+			fmt.Fprintf(&b, "<i>%s</i>", html.EscapeString(string(t)))
+		case *comment.Link:
+			fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(t.URL), htmlText(t.Text))
+		case *comment.DocLink:
+			u := t.DefaultURL("https://pkg.go.dev")
+			fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(u), htmlText(t.Text))
+		}
+	}
+	return b.String()
+}
+
+func writeHTMLBegin(w io.Writer, title string) {
+	fmt.Fprintln(w, "<!DOCTYPE html>")
+	fmt.Fprintln(w, "<html>")
+	fmt.Fprintln(w, "<head>")
+	fmt.Fprintln(w, `<meta charset="utf-8">`)
+	fmt.Fprintln(w, `<meta name="viewport" content="width=device-width, initial-scale=1">`)
+	fmt.Fprintf(w, "<title>%s</title>\n", html.EscapeString(title))
+	fmt.Fprintf(w, "<style>\n%s</style>\n", stylesheet)
+	fmt.Fprintln(w, "</head>")
+	fmt.Fprintln(w, "<body>")
+	fmt.Fprintln(w, "<main>")
+}
+
+func writeHTMLEnd(w io.Writer) {
+	fmt.Fprintln(w, "</main>")
+	fmt.Fprintln(w, "</body>")
+	fmt.Fprintln(w, "</html>")
+}
+
+// indexEntry is a manual listed in index.html.
+type indexEntry struct {
+	Name     string
+	Section  string
+	Synopsis string
+
+	// File is the path of the manual relative to index.html.
+	File string
+}
+
+// writeHTMLIndex writes index.html that links to entries.
+func writeHTMLIndex(w io.Writer, entries []*indexEntry) error {
+	var buf bytes.Buffer
+	writeHTMLBegin(&buf, "Manuals")
+	fmt.Fprintln(&buf, "<h1>Manuals</h1>")
+	fmt.Fprintln(&buf, "<dl>")
+	for _, e := range entries {
+		fmt.Fprintf(&buf, `<dt><a href="%s">%s(%s)</a></dt>`+"\n", html.EscapeString(e.File), html.EscapeString(e.Name), e.Section)
+		fmt.Fprintf(&buf, "<dd>%s</dd>\n", html.EscapeString(e.Synopsis))
+	}
+	fmt.Fprintln(&buf, "</dl>")
+	writeHTMLEnd(&buf)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
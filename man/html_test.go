@@ -0,0 +1,57 @@
+package man
+
+import (
+	"go/doc/comment"
+	"strings"
+	"testing"
+)
+
+func TestHTMLText(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"a < b & c", "a &lt; b &amp; c"},
+		{"see [Go].\n\n[Go]: https://go.dev/?a=1&b=2", `see <a href="https://go.dev/?a=1&amp;b=2">Go</a>.`},
+		{"see [fmt.Println]", `see <a href="https://pkg.go.dev/fmt#Println">fmt.Println</a>`},
+	}
+	for _, tt := range tests {
+		var parser comment.Parser
+		d := parser.Parse(tt.s)
+		p := d.Content[0].(*comment.Paragraph)
+		if s := htmlText(p.Text); s != tt.want {
+			t.Errorf("htmlText(%q) = %q; want %q", tt.s, s, tt.want)
+		}
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	var b strings.Builder
+	entries := []*indexEntry{
+		{Name: "cmd", Section: "1", Synopsis: "is a <command>.", File: "man1/cmd.html"},
+	}
+	if err := writeHTMLIndex(&b, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := `<dt><a href="man1/cmd.html">cmd(1)</a></dt>` + "\n<dd>is a &lt;command&gt;.</dd>\n"
+	if s := b.String(); !strings.Contains(s, want) {
+		t.Errorf("writeHTMLIndex() = %q; want to contain %q", s, want)
+	}
+}
+
+func TestHTMLSymbols(t *testing.T) {
+	var parser comment.Parser
+	s := &Section{
+		Symbols: []*Symbol{
+			{Kind: FuncSymbol, Name: "Generate", Named: true, Content: parser.Parse("generates manuals.").Content},
+			{Kind: TypeSymbol, Name: "Config", Named: true, Article: "A", Content: parser.Parse("is the configuration.").Content},
+		},
+	}
+	var buf strings.Builder
+	p := newHTMLPrinter(&buf)
+	p.writeSection(s, 3)
+	want := "<p><b>Generate()</b> generates manuals.</p>\n<p>A <b>Config</b> is the configuration.</p>\n"
+	if v := buf.String(); v != want {
+		t.Errorf("writeSection() = %q; want %q", v, want)
+	}
+}
@@ -0,0 +1,272 @@
+package man
+
+import (
+	"encoding/json"
+	"go/doc/comment"
+	"io"
+)
+
+// jsonPrinter writes manuals as JSON documents.
+type jsonPrinter struct {
+	w   io.Writer
+	err error
+}
+
+func newJSONPrinter(w io.Writer) *jsonPrinter {
+	return &jsonPrinter{w, nil}
+}
+
+func (p *jsonPrinter) Err() error {
+	return p.err
+}
+
+func (p *jsonPrinter) Page(m *ManPage) {
+	if p.err != nil {
+		return
+	}
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "\t")
+	p.err = enc.Encode(newJSONPage(m))
+}
+
+type jsonPage struct {
+	Name        string         `json:"name"`
+	Section     string         `json:"section"`
+	Description string         `json:"description"`
+	Synopsis    *jsonSynopsis  `json:"synopsis,omitempty"`
+	Options     []*jsonFlag    `json:"options,omitempty"`
+	Sections    []*jsonSection `json:"sections,omitempty"`
+	SeeAlso     []*jsonRef     `json:"seeAlso,omitempty"`
+	Bugs        []string       `json:"bugs,omitempty"`
+	Date        string         `json:"date,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	Manual      string         `json:"manual,omitempty"`
+	Version     string         `json:"version,omitempty"`
+}
+
+type jsonSynopsis struct {
+	Command string          `json:"command,omitempty"`
+	Args    []string        `json:"args,omitempty"`
+	Import  string          `json:"import,omitempty"`
+	Consts  []string        `json:"consts,omitempty"`
+	Vars    []string        `json:"vars,omitempty"`
+	Types   []*jsonTypeDecl `json:"types,omitempty"`
+	Funcs   []string        `json:"funcs,omitempty"`
+}
+
+type jsonTypeDecl struct {
+	Decl   string   `json:"decl"`
+	Consts []string `json:"consts,omitempty"`
+	Funcs  []string `json:"funcs,omitempty"`
+}
+
+type jsonFlag struct {
+	Name        string   `json:"name"`
+	Shorthand   string   `json:"shorthand,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Names       []string `json:"names"`
+	Kind        string   `json:"kind"`
+	Type        string   `json:"type,omitempty"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Usage       string   `json:"usage"`
+	Default     string   `json:"default,omitempty"`
+	Env         []string `json:"env,omitempty"`
+}
+
+type jsonSection struct {
+	Name        string            `json:"name"`
+	Content     []*jsonBlock      `json:"content,omitempty"`
+	Definitions []*jsonDefinition `json:"definitions,omitempty"`
+	Symbols     []*jsonSymbol     `json:"symbols,omitempty"`
+	Subsections []*jsonSection    `json:"subsections,omitempty"`
+}
+
+type jsonDefinition struct {
+	Kind string   `json:"kind"`
+	Term string   `json:"term"`
+	Doc  string   `json:"doc,omitempty"`
+	Ref  *jsonRef `json:"ref,omitempty"`
+}
+
+type jsonRef struct {
+	Name    string `json:"name"`
+	Section string `json:"section"`
+}
+
+type jsonSymbol struct {
+	Kind    string       `json:"kind"`
+	Name    string       `json:"name"`
+	Named   bool         `json:"named"`
+	Article string       `json:"article,omitempty"`
+	Content []*jsonBlock `json:"content,omitempty"`
+}
+
+// jsonBlock is a block of the document.
+// Type is one of "heading", "paragraph", "code" or "list".
+type jsonBlock struct {
+	Type    string      `json:"type"`
+	Text    []*jsonText `json:"text,omitempty"`
+	Code    string      `json:"code,omitempty"`
+	Ordered bool        `json:"ordered,omitempty"`
+	Items   []*jsonItem `json:"items,omitempty"`
+}
+
+type jsonItem struct {
+	Number  string       `json:"number,omitempty"`
+	Content []*jsonBlock `json:"content"`
+}
+
+// jsonText is a span of the text.
+// Type is one of "plain", "italic" or "link".
+type jsonText struct {
+	Type string      `json:"type"`
+	Text string      `json:"text,omitempty"`
+	URL  string      `json:"url,omitempty"`
+	Link []*jsonText `json:"link,omitempty"`
+}
+
+var (
+	flagKindNames = map[FlagKind]string{
+		ValueFlag: "value",
+		BoolFlag:  "bool",
+		CountFlag: "count",
+	}
+	definitionKindNames = map[DefinitionKind]string{
+		CommandDefinition:    "command",
+		EnvDefinition:        "env",
+		ExitStatusDefinition: "exitStatus",
+	}
+	symbolKindNames = map[SymbolKind]string{
+		ConstSymbol: "const",
+		VarSymbol:   "var",
+		TypeSymbol:  "type",
+		FuncSymbol:  "func",
+	}
+)
+
+func newJSONPage(m *ManPage) *jsonPage {
+	v := &jsonPage{
+		Name:        m.Name,
+		Section:     m.Section,
+		Description: m.Description,
+		Bugs:        m.Bugs,
+		Date:        formatDate(m.Date, dateLayout),
+		Source:      m.Source,
+		Manual:      m.Manual,
+		Version:     m.Version,
+	}
+	if s := m.Synopsis; s != nil {
+		v.Synopsis = &jsonSynopsis{
+			Command: s.Command,
+			Args:    s.Args,
+			Import:  s.Import,
+			Consts:  s.Consts,
+			Vars:    s.Vars,
+			Funcs:   s.Funcs,
+		}
+		for _, t := range s.Types {
+			v.Synopsis.Types = append(v.Synopsis.Types, &jsonTypeDecl{t.Decl, t.Consts, t.Funcs})
+		}
+	}
+	for _, flg := range m.Options {
+		names := []string{"-" + flg.Name}
+		if flg.Long {
+			names = optionNames(flg)
+		}
+		v.Options = append(v.Options, &jsonFlag{
+			Name:        flg.Name,
+			Shorthand:   flg.Shorthand,
+			Aliases:     flg.Aliases,
+			Names:       names,
+			Kind:        flagKindNames[flg.Kind],
+			Type:        flg.Type,
+			Placeholder: flg.Placeholder,
+			Usage:       flg.Usage,
+			Default:     flg.Default,
+			Env:         flg.Env,
+		})
+	}
+	for _, s := range m.Sections {
+		v.Sections = append(v.Sections, newJSONSection(s))
+	}
+	for _, ref := range m.SeeAlso {
+		v.SeeAlso = append(v.SeeAlso, &jsonRef{ref.Name, ref.Section})
+	}
+	return v
+}
+
+func newJSONSection(s *Section) *jsonSection {
+	v := &jsonSection{
+		Name:    s.Name,
+		Content: newJSONBlocks(s.Content),
+	}
+	for _, d := range s.Definitions {
+		def := &jsonDefinition{
+			Kind: definitionKindNames[d.Kind],
+			Term: d.Term,
+			Doc:  d.Doc,
+		}
+		if d.Ref != nil {
+			def.Ref = &jsonRef{d.Ref.Name, d.Ref.Section}
+		}
+		v.Definitions = append(v.Definitions, def)
+	}
+	for _, sym := range s.Symbols {
+		v.Symbols = append(v.Symbols, &jsonSymbol{
+			Kind:    symbolKindNames[sym.Kind],
+			Name:    sym.Name,
+			Named:   sym.Named,
+			Article: sym.Article,
+			Content: newJSONBlocks(sym.Content),
+		})
+	}
+	for _, sub := range s.Subsections {
+		v.Subsections = append(v.Subsections, newJSONSection(sub))
+	}
+	return v
+}
+
+func newJSONBlocks(content []comment.Block) []*jsonBlock {
+	var a []*jsonBlock
+	for _, c := range content {
+		switch c := c.(type) {
+		case *comment.Heading:
+			a = append(a, &jsonBlock{Type: "heading", Text: newJSONText(c.Text)})
+		case *comment.Paragraph:
+			a = append(a, &jsonBlock{Type: "paragraph", Text: newJSONText(c.Text)})
+		case *comment.Code:
+			a = append(a, &jsonBlock{Type: "code", Code: c.Text})
+		case *comment.List:
+			b := &jsonBlock{Type: "list"}
+			for _, item := range c.Items {
+				if item.Number != "" {
+					b.Ordered = true
+				}
+				b.Items = append(b.Items, &jsonItem{
+					Number:  item.Number,
+					Content: newJSONBlocks(item.Content),
+				})
+			}
+			a = append(a, b)
+		}
+	}
+	return a
+}
+
+func newJSONText(a []comment.Text) []*jsonText {
+	var spans []*jsonText
+	for _, t := range a {
+		switch t := t.(type) {
+		case comment.Plain:
+			spans = append(spans, &jsonText{Type: "plain", Text: string(t)})
+		case comment.Italic:
+			spans = append(spans, &jsonText{Type: "italic", Text: string(t)})
+		case *comment.Link:
+			spans = append(spans, &jsonText{Type: "link", URL: t.URL, Link: newJSONText(t.Text)})
+		case *comment.DocLink:
+			u := t.DefaultURL("https://pkg.go.dev")
+			spans = append(spans, &jsonText{Type: "link", URL: u, Link: newJSONText(t.Text)})
+		}
+	}
+	return spans
+}
@@ -0,0 +1,93 @@
+package man
+
+import (
+	"encoding/json"
+	"go/doc/comment"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONPrinter(t *testing.T) {
+	var parser comment.Parser
+	m := &ManPage{
+		Name:        "cmd",
+		Section:     "1",
+		Description: "is a command.",
+		Options: []*Flag{
+			{Name: "o", Placeholder: "file", Usage: "write to file", Type: "string", Default: "a.out"},
+		},
+		Sections: []*Section{
+			{Name: "OVERVIEW", Content: parser.Parse("See [Go].\n\n[Go]: https://go.dev/").Content},
+		},
+	}
+	var buf strings.Builder
+	p := newJSONPrinter(&buf)
+	p.Page(m)
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Name    string
+		Options []struct {
+			Names   []string
+			Kind    string
+			Default string
+		}
+		Sections []struct {
+			Content []struct {
+				Type string
+				Text []struct {
+					Type string
+					URL  string
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "cmd" {
+		t.Errorf("name = %q; want %q", v.Name, "cmd")
+	}
+	if want := []string{"-o"}; len(v.Options) != 1 || !reflect.DeepEqual(v.Options[0].Names, want) || v.Options[0].Kind != "value" || v.Options[0].Default != "a.out" {
+		t.Errorf("options = %+v; want [{Names: %v, Kind: value, Default: a.out}]", v.Options, want)
+	}
+	text := v.Sections[0].Content[0].Text
+	if len(text) != 3 || text[1].Type != "link" || text[1].URL != "https://go.dev/" {
+		t.Errorf("text = %+v; want a link to https://go.dev/", text)
+	}
+}
+
+func TestJSONPrinterSynopsis(t *testing.T) {
+	m := &ManPage{
+		Name:        "pkg",
+		Section:     "3",
+		Description: "is a library.",
+		Synopsis: &Synopsis{
+			Import: "example.com/pkg",
+			Types:  []*TypeDecl{{Decl: "type T int", Funcs: []string{"func New() T"}}},
+		},
+	}
+	var buf strings.Builder
+	p := newJSONPrinter(&buf)
+	p.Page(m)
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Synopsis map[string]any
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &v); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"import": "example.com/pkg",
+		"types": []any{
+			map[string]any{"decl": "type T int", "funcs": []any{"func New() T"}},
+		},
+	}
+	if !reflect.DeepEqual(v.Synopsis, want) {
+		t.Errorf("synopsis = %v; want %v", v.Synopsis, want)
+	}
+}
@@ -0,0 +1,171 @@
+package man
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const kongPath = "github.com/alecthomas/kong"
+
+// findKongFlags retrieves flags from struct tags of the grammar passed to github.com/alecthomas/kong package.
+// Fields that have the tags in the form of `kong:"..."` are reported to warn unless it is nil.
+//
+// BUG(lufia): Generate with Flag "kong" doesn't read the tags in the form of `kong:"..."`.
+func findKongFlags(info *types.Info, fset *token.FileSet, files []*ast.File, warn func(msg string)) *commandInfo {
+	st := kongGrammar(info, files)
+	if st == nil {
+		return &commandInfo{}
+	}
+	var (
+		flags []*Flag
+		cmds  []*subcommand
+	)
+	var walk func(st *types.Struct, cmd string)
+	walk = func(st *types.Struct, cmd string) {
+		for i := range st.NumFields() {
+			f := st.Field(i)
+			tag := reflect.StructTag(st.Tag(i))
+			if !f.Exported() || tag.Get("kong") == "-" {
+				continue
+			}
+			if tag.Get("kong") != "" && warn != nil {
+				warn(fmt.Sprintf("%v: kong tag of %s is not supported; ignored", fset.Position(f.Pos()), f.Name()))
+			}
+			if hasTag(tag, "hidden") || hasTag(tag, "arg") {
+				continue
+			}
+			name := tag.Get("name")
+			if name == "" {
+				name = dashedString(f.Name())
+			}
+			if hasTag(tag, "cmd") {
+				if sub := structOf(f.Type()); sub != nil {
+					cmds = append(cmds, &subcommand{
+						Name:     name,
+						Synopsis: tag.Get("help"),
+					})
+					walk(sub, name)
+				}
+				continue
+			}
+			if f.Embedded() || hasTag(tag, "embed") {
+				if sub := structOf(f.Type()); sub != nil {
+					walk(sub, cmd)
+					continue
+				}
+			}
+			placeholder := tag.Get("placeholder")
+			if placeholder == "" {
+				placeholder = name
+			}
+			typ := basicType(f.Type())
+			if typ == "bool" {
+				placeholder = ""
+			}
+			flags = append(flags, &Flag{
+				Name:        name,
+				Shorthand:   tag.Get("short"),
+				Long:        true,
+				Kind:        switchKind(typ),
+				Aliases:     splitTag(tag.Get("aliases")),
+				Env:         splitTag(tag.Get("env")),
+				Placeholder: placeholder,
+				Usage:       tag.Get("help"),
+				FlagSet:     cmd,
+				Type:        typ,
+				Default:     nonZero(typ, tag.Get("default")),
+			})
+		}
+	}
+	walk(st, "")
+	own, subcmds := groupFlags("", flags)
+	return &commandInfo{
+		Flags:       own,
+		Subcommands: mergeSubcommands(cmds, subcmds),
+	}
+}
+
+// kongGrammar returns the struct that is passed to kong.Parse, kong.New or kong.Must.
+func kongGrammar(info *types.Info, files []*ast.File) *types.Struct {
+	var st *types.Struct
+	for _, f := range files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || st != nil {
+				return st == nil
+			}
+			obj := typeutil.Callee(info, call)
+			if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() != kongPath {
+				return true
+			}
+			switch obj.Name() {
+			case "Parse", "New", "Must":
+				if len(call.Args) > 0 {
+					st = structOf(info.TypeOf(call.Args[0]))
+				}
+			}
+			return true
+		})
+	}
+	return st
+}
+
+func hasTag(tag reflect.StructTag, key string) bool {
+	_, ok := tag.Lookup(key)
+	return ok
+}
+
+func splitTag(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// structOf returns the struct type that t or *t is.
+func structOf(t types.Type) *types.Struct {
+	if t == nil {
+		return nil
+	}
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, _ := t.Underlying().(*types.Struct)
+	return st
+}
+
+// basicType returns the name of the underlying basic type of t.
+// It returns an empty string if t is not a basic type.
+func basicType(t types.Type) string {
+	if b, ok := t.Underlying().(*types.Basic); ok {
+		return b.Name()
+	}
+	return ""
+}
+
+// dashedString converts the camel case s to lower-case words joined by dashes,
+// like kong does. For example, "HTTPPort" is converted to "http-port".
+func dashedString(s string) string {
+	var (
+		words []string
+		r     = []rune(s)
+		start = 0
+	)
+	for i := 1; i < len(r); i++ {
+		lower := unicode.IsLower(r[i-1]) && unicode.IsUpper(r[i])
+		acronym := i+1 < len(r) && unicode.IsUpper(r[i-1]) && unicode.IsUpper(r[i]) && unicode.IsLower(r[i+1])
+		if lower || acronym {
+			words = append(words, string(r[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(r[start:]))
+	return strings.ToLower(strings.Join(words, "-"))
+}
@@ -0,0 +1,295 @@
+package man
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc/comment"
+	"io"
+	"strings"
+)
+
+// markdownPrinter writes manuals as Markdown documents.
+type markdownPrinter struct {
+	w   io.Writer
+	err error
+}
+
+func newMarkdownPrinter(w io.Writer) *markdownPrinter {
+	return &markdownPrinter{w, nil}
+}
+
+func (p *markdownPrinter) Err() error {
+	return p.err
+}
+
+func (p *markdownPrinter) Write(data []byte) (n int, err error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	n, p.err = p.w.Write(data)
+	return n, p.err
+}
+
+func (p *markdownPrinter) Page(m *ManPage) {
+	fmt.Fprintf(p, "# %s(%s)\n", mdEscape(m.Name), m.Section)
+	fmt.Fprintln(p, "\n## NAME")
+	fmt.Fprintf(p, "\n%s - %s\n", mdEscape(m.Name), mdEscape(m.Description))
+	p.writeSynopsis(m.Synopsis, len(m.Options) > 0)
+	p.writeOptions(m.Options)
+	for _, s := range m.Sections {
+		fmt.Fprintf(p, "\n## %s\n", mdEscape(strings.ToUpper(sectionTitle(s))))
+		p.writeSection(s, "###")
+	}
+	if len(m.SeeAlso) > 0 {
+		fmt.Fprintln(p, "\n## SEE ALSO")
+		a := make([]string, len(m.SeeAlso))
+		for i, ref := range m.SeeAlso {
+			a[i] = mdRef(ref)
+		}
+		fmt.Fprintf(p, "\n%s\n", strings.Join(a, ", "))
+	}
+	if len(m.Bugs) > 0 {
+		fmt.Fprintln(p, "\n## BUGS")
+		for _, s := range m.Bugs {
+			fmt.Fprintln(p, "")
+			p.writeLines("", mdEscape(strings.TrimSpace(s)))
+		}
+	}
+	if a := m.footer(); len(a) > 0 {
+		fmt.Fprintln(p, "\n---")
+		fmt.Fprintf(p, "\n%s\n", mdEscape(strings.Join(a, " — ")))
+	}
+}
+
+// mdRef returns the link to the page that ref refers to.
+// The page is expected in the same directory.
+func mdRef(ref *Reference) string {
+	file := manualFileName(ref.Path, ".md")
+	return fmt.Sprintf("[%s(%s)](%s)", mdEscape(ref.Name), ref.Section, file)
+}
+
+func (p *markdownPrinter) writeSynopsis(s *Synopsis, hasOptions bool) {
+	if s == nil {
+		return
+	}
+	fmt.Fprintln(p, "\n## SYNOPSIS")
+	if s.Import == "" {
+		line := "**" + mdEscape(s.Command) + "**"
+		if hasOptions {
+			line += " [*options*]"
+		}
+		for _, arg := range s.Args {
+			line += " *" + mdEscape(arg) + "*"
+		}
+		fmt.Fprintf(p, "\n%s\n", line)
+		return
+	}
+	fmt.Fprintln(p, "\n```go")
+	fmt.Fprintf(p, "import %q\n", s.Import)
+	for _, c := range s.Consts {
+		fmt.Fprintf(p, "\n%s\n", c)
+	}
+	for _, v := range s.Vars {
+		fmt.Fprintf(p, "\n%s\n", v)
+	}
+	for _, t := range s.Types {
+		fmt.Fprintf(p, "\n%s\n", t.Decl)
+		for _, c := range t.Consts {
+			fmt.Fprintf(p, "%s\n", c)
+		}
+		for _, f := range t.Funcs {
+			fmt.Fprintf(p, "%s\n", f)
+		}
+	}
+	if len(s.Funcs) > 0 {
+		fmt.Fprintln(p, "")
+	}
+	for _, f := range s.Funcs {
+		fmt.Fprintf(p, "%s\n", f)
+	}
+	fmt.Fprintln(p, "```")
+}
+
+func (p *markdownPrinter) writeOptions(flags []*Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintln(p, "\n## OPTIONS")
+	fmt.Fprintln(p, "")
+	for _, flg := range flags {
+		names := []string{"-" + flg.Name}
+		if flg.Long {
+			names = optionNames(flg)
+		}
+		a := make([]string, len(names))
+		for i, name := range names {
+			a[i] = "`" + name + "`"
+		}
+		s := strings.Join(a, ", ")
+		if flg.Placeholder != "" {
+			s += "=*" + mdEscape(flg.Placeholder) + "*"
+		}
+		fmt.Fprintf(p, "- %s: %s", s, mdEscape(flg.Usage+defaultText(flg)))
+		if len(flg.Env) > 0 {
+			a := make([]string, len(flg.Env))
+			for i, s := range flg.Env {
+				a[i] = "`$" + s + "`"
+			}
+			fmt.Fprintf(p, " [%s]", strings.Join(a, ", "))
+		}
+		fmt.Fprintln(p, "")
+	}
+}
+
+// writeSection writes the body of s; heading is the prefix for the headings of subsections.
+func (p *markdownPrinter) writeSection(s *Section, heading string) {
+	p.writeContent(s.Content, "")
+	if len(s.Definitions) > 0 {
+		fmt.Fprintln(p, "")
+		for _, d := range s.Definitions {
+			switch d.Kind {
+			case CommandDefinition:
+				fmt.Fprintf(p, "- **%s**", mdEscape(d.Term))
+			default:
+				fmt.Fprintf(p, "- `%s`", d.Term)
+			}
+			var a []string
+			switch {
+			case d.Doc == "":
+			case d.Kind == CommandDefinition:
+				a = append(a, mdEscape(d.Doc))
+			default:
+				a = append(a, mdEscape(strings.Join(strings.Fields(d.Doc), " ")))
+			}
+			if d.Ref != nil {
+				a = append(a, fmt.Sprintf("See %s.", mdRef(d.Ref)))
+			}
+			if len(a) > 0 {
+				fmt.Fprintf(p, ": %s", strings.Join(a, " "))
+			}
+			fmt.Fprintln(p, "")
+		}
+	}
+	for _, sym := range s.Symbols {
+		content := sym.Content
+		if sym.Named {
+			name := sym.Name
+			if sym.Kind == FuncSymbol {
+				name += "()"
+			}
+			lead := "**" + mdEscape(name) + "**"
+			if sym.Article != "" {
+				lead = sym.Article + " " + lead
+			}
+			// The first paragraph continues the sentence that begins with the name.
+			if len(content) > 0 {
+				if c, ok := content[0].(*comment.Paragraph); ok {
+					fmt.Fprintln(p, "")
+					p.writeLines("", lead+" "+mdText(c.Text))
+					content = content[1:]
+				}
+			}
+		}
+		p.writeContent(content, "")
+	}
+	for _, sub := range s.Subsections {
+		fmt.Fprintf(p, "\n%s %s\n", heading, mdEscape(sub.Name))
+		p.writeSection(sub, heading+"#")
+	}
+}
+
+// writeContent writes content with indent for each line.
+// Blocks are separated by a blank line.
+func (p *markdownPrinter) writeContent(content []comment.Block, indent string) {
+	for i, c := range content {
+		if i > 0 || indent == "" {
+			fmt.Fprintln(p, "")
+		}
+		switch c := c.(type) {
+		case *comment.Heading:
+			fmt.Fprintf(p, "## %s\n", strings.ToUpper(mdText(c.Text)))
+		case *comment.Paragraph:
+			p.writeLines(indent, mdText(c.Text))
+		case *comment.Code:
+			fence := codeFence(c.Text)
+			fmt.Fprintf(p, "%s%s\n", indent, fence)
+			p.writeLines(indent, c.Text)
+			fmt.Fprintf(p, "%s%s\n", indent, fence)
+		case *comment.List:
+			for j, item := range c.Items {
+				marker := "- "
+				if item.Number != "" {
+					marker = item.Number + ". "
+				}
+				if j > 0 && c.BlankBetween() {
+					fmt.Fprintln(p, "")
+				}
+				// The first line follows the marker, the rest are indented to the marker's width.
+				var buf bytes.Buffer
+				q := newMarkdownPrinter(&buf)
+				q.writeContent(item.Content, strings.Repeat(" ", len(marker)))
+				s := strings.TrimLeft(buf.String(), " \n")
+				fmt.Fprintf(p, "%s%s%s", indent, marker, s)
+				if q.err != nil {
+					p.err = q.err
+				}
+			}
+		}
+	}
+}
+
+// writeLines writes each line of s with indent.
+func (p *markdownPrinter) writeLines(indent, s string) {
+	for line := range strings.Lines(s) {
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			fmt.Fprintln(p, "")
+			continue
+		}
+		fmt.Fprintf(p, "%s%s\n", indent, line)
+	}
+}
+
+// mdText returns the Markdown representation of a.
+func mdText(a []comment.Text) string {
+	var b strings.Builder
+	for _, t := range a {
+		switch t := t.(type) {
+		case comment.Plain:
+			b.WriteString(mdEscape(string(t)))
+		case comment.Italic:
+			fmt.Fprintf(&b, "*%s*", mdEscape(string(t)))
+		case *comment.Link:
+			fmt.Fprintf(&b, "[%s](%s)", mdText(t.Text), t.URL)
+		case *comment.DocLink:
+			u := t.DefaultURL("https://pkg.go.dev")
+			fmt.Fprintf(&b, "[%s](%s)", mdText(t.Text), u)
+		}
+	}
+	return b.String()
+}
+
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+)
+
+// mdEscape escapes characters that have special meanings in Markdown inline text.
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// codeFence returns the fence that is longer than any backquote sequence in s.
+func codeFence(s string) string {
+	fence := "```"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	return fence
+}
@@ -0,0 +1,41 @@
+package man
+
+import (
+	"go/doc/comment"
+	"testing"
+)
+
+func TestMdText(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"a_b *c*", `a\_b \*c\*`},
+		{"see [Go].\n\n[Go]: https://go.dev/", "see [Go](https://go.dev/)."},
+		{"see [fmt.Println]", "see [fmt.Println](https://pkg.go.dev/fmt#Println)"},
+	}
+	for _, tt := range tests {
+		var parser comment.Parser
+		d := parser.Parse(tt.s)
+		p := d.Content[0].(*comment.Paragraph)
+		if s := mdText(p.Text); s != tt.want {
+			t.Errorf("mdText(%q) = %q; want %q", tt.s, s, tt.want)
+		}
+	}
+}
+
+func TestCodeFence(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"fmt.Println()", "```"},
+		{"```go\n```", "````"},
+	}
+	for _, tt := range tests {
+		if s := codeFence(tt.s); s != tt.want {
+			t.Errorf("codeFence(%q) = %q; want %q", tt.s, s, tt.want)
+		}
+	}
+}
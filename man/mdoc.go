@@ -0,0 +1,354 @@
+package man
+
+import (
+	"fmt"
+	"go/doc/comment"
+	"io"
+	"strings"
+
+	"github.com/lufia/godoc2man/internal/roff"
+)
+
+// mdocPrinter writes manuals with semantic mdoc(7) macros.
+type mdocPrinter struct {
+	w   io.Writer
+	err error
+
+	// macro is the last macro line that is not terminated yet.
+	// Closing punctuations just after the macro are appended to it.
+	macro string
+}
+
+func newMdocPrinter(w io.Writer) *mdocPrinter {
+	return &mdocPrinter{w: w}
+}
+
+func (p *mdocPrinter) Err() error {
+	return p.err
+}
+
+func (p *mdocPrinter) Write(data []byte) (n int, err error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	n, p.err = p.w.Write(data)
+	return n, p.err
+}
+
+func (p *mdocPrinter) Page(m *ManPage) {
+	// mdoc(7) derives the manual title from the section; m.Manual is not written.
+	if date := formatDate(m.Date, "January 2, 2006"); date != "" {
+		fmt.Fprintf(p, ".Dd %s\n", date)
+	} else {
+		fmt.Fprintln(p, ".Dd $Mdocdate$")
+	}
+	fmt.Fprintf(p, ".Dt %s %s\n", roff.Str(strings.ToUpper(m.Name)), m.Section)
+	if m.Source != "" {
+		fmt.Fprintf(p, ".Os %s\n", roff.Str(m.Source))
+	} else {
+		fmt.Fprintln(p, ".Os")
+	}
+	fmt.Fprintln(p, ".Sh NAME")
+	fmt.Fprintf(p, ".Nm %s\n", roff.Str(m.Name))
+	fmt.Fprintf(p, ".Nd %s\n", roff.Str(m.Description))
+	p.writeSynopsis(m.Synopsis, m.Options)
+	p.writeOptions(m.Options)
+	for _, s := range m.Sections {
+		fmt.Fprintf(p, ".Sh %s\n", roff.Str(strings.ToUpper(s.Name)))
+		p.writeSection(s)
+	}
+	if len(m.SeeAlso) > 0 {
+		fmt.Fprintln(p, ".Sh SEE ALSO")
+		for i, ref := range m.SeeAlso {
+			fmt.Fprintf(p, ".Xr %s %s", roff.Str(ref.Name), ref.Section)
+			if i < len(m.SeeAlso)-1 {
+				fmt.Fprint(p, " ,")
+			}
+			fmt.Fprintln(p, "")
+		}
+	}
+	if len(m.Bugs) > 0 {
+		fmt.Fprintln(p, ".Sh BUGS")
+		for _, s := range m.Bugs {
+			fmt.Fprintln(p, ".Pp")
+			p.writeText(s)
+		}
+	}
+}
+
+func (p *mdocPrinter) writeSynopsis(s *Synopsis, flags []*Flag) {
+	if s == nil {
+		return
+	}
+	fmt.Fprintln(p, ".Sh SYNOPSIS")
+	if s.Import == "" {
+		// The first word is the name of the command, and the rest are subcommands.
+		a := strings.Fields(s.Command)
+		if len(a) == 1 {
+			// Nm macro without arguments refers to the name of the page.
+			fmt.Fprintln(p, ".Nm")
+		} else {
+			fmt.Fprintf(p, ".Nm %s\n", roff.Str(a[0]))
+		}
+		for _, sub := range a[1:] {
+			fmt.Fprintf(p, ".Cm %s\n", roff.Str(sub))
+		}
+		for _, flg := range flags {
+			name := "-" + flg.Name
+			if flg.Long {
+				name = optionNames(flg)[0]
+			}
+			fmt.Fprintf(p, ".Op %s\n", mdocOption(flg, []string{name}))
+		}
+		for _, arg := range s.Args {
+			fmt.Fprintf(p, ".Ar %s\n", roff.Str(arg))
+		}
+		return
+	}
+	fmt.Fprintln(p, ".Bd -literal")
+	fmt.Fprintf(p, "import %q\n", roff.Str(s.Import))
+	for _, c := range s.Consts {
+		fmt.Fprintln(p, "")
+		p.writeLiteral(c)
+	}
+	for _, v := range s.Vars {
+		fmt.Fprintln(p, "")
+		p.writeLiteral(v)
+	}
+	for _, t := range s.Types {
+		fmt.Fprintln(p, "")
+		p.writeLiteral(t.Decl)
+		for _, c := range t.Consts {
+			p.writeLiteral(c)
+		}
+		for _, f := range t.Funcs {
+			p.writeLiteral(f)
+		}
+	}
+	if len(s.Funcs) > 0 {
+		fmt.Fprintln(p, "")
+	}
+	for _, f := range s.Funcs {
+		p.writeLiteral(f)
+	}
+	fmt.Fprintln(p, ".Ed")
+}
+
+func (p *mdocPrinter) writeOptions(flags []*Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintln(p, ".Sh OPTIONS")
+	fmt.Fprintln(p, ".Bl -tag -width Ds")
+	for _, flg := range flags {
+		names := []string{"-" + flg.Name}
+		if flg.Long {
+			names = optionNames(flg)
+		}
+		fmt.Fprintf(p, ".It %s\n", mdocOption(flg, names))
+		p.writeText(flg.Usage + defaultText(flg))
+		if len(flg.Env) > 0 {
+			a := make([]string, len(flg.Env))
+			for i, s := range flg.Env {
+				a[i] = fmt.Sprintf("Ev %s", roff.Str(s))
+			}
+			fmt.Fprintf(p, ".Bq %s\n", strings.Join(a, " , "))
+		}
+	}
+	fmt.Fprintln(p, ".El")
+}
+
+// mdocOption returns the macro arguments of flg that is named as names.
+// Each name of names must have leading dashes.
+func mdocOption(flg *Flag, names []string) string {
+	a := make([]string, len(names))
+	for i, name := range names {
+		// Fl macro prepends a dash to the name.
+		a[i] = fmt.Sprintf("Fl %s", roff.Str(name[1:]))
+	}
+	s := strings.Join(a, " , ")
+	if flg.Placeholder != "" {
+		s += fmt.Sprintf(" Ns = Ns Ar %s", roff.Str(flg.Placeholder))
+	}
+	return s
+}
+
+func (p *mdocPrinter) writeSection(s *Section) {
+	p.writeContent(s.Content, 0, false)
+	if len(s.Definitions) > 0 {
+		fmt.Fprintln(p, ".Bl -tag -width Ds")
+		for _, d := range s.Definitions {
+			switch d.Kind {
+			case CommandDefinition:
+				fmt.Fprintf(p, ".It Cm %s\n", roff.Str(d.Term))
+			case EnvDefinition:
+				fmt.Fprintf(p, ".It Ev %s\n", roff.Str(d.Term))
+			default:
+				fmt.Fprintf(p, ".It %s\n", roff.Str(d.Term))
+			}
+			p.writeText(d.Doc)
+			if d.Ref != nil {
+				fmt.Fprintf(p, "See\n.Xr %s %s .\n", roff.Str(d.Ref.Name), d.Ref.Section)
+			}
+		}
+		fmt.Fprintln(p, ".El")
+	}
+	for _, sym := range s.Symbols {
+		fmt.Fprintln(p, ".Pp")
+		if sym.Named {
+			if sym.Article != "" {
+				fmt.Fprintln(p, sym.Article)
+			}
+			fmt.Fprintf(p, "%s %s\n", symbolMacros[sym.Kind], roff.Str(sym.Name))
+		}
+		// The first paragraph continues the sentence that begins with the name,
+		// or it follows the Pp macro above.
+		p.writeContent(sym.Content, 0, true)
+	}
+	for _, sub := range s.Subsections {
+		fmt.Fprintf(p, ".Ss %s\n", roff.Str(sub.Name))
+		p.writeSection(sub)
+	}
+}
+
+var symbolMacros = map[SymbolKind]string{
+	ConstSymbol: ".Dv",
+	VarSymbol:   ".Va",
+	TypeSymbol:  ".Vt",
+	FuncSymbol:  ".Fn",
+}
+
+func (p *mdocPrinter) writeContent(content []comment.Block, depth int, cont bool) {
+	for _, c := range content {
+		switch c := c.(type) {
+		case *comment.Heading:
+			fmt.Fprintf(p, ".Sh %s\n", roff.Str(strings.ToUpper(plainText(c.Text))))
+		case *comment.Paragraph:
+			if depth == 0 && !cont {
+				fmt.Fprintln(p, ".Pp")
+			}
+			p.writeInline(c.Text)
+		case *comment.Code:
+			fmt.Fprintln(p, ".Bd -literal -offset indent")
+			p.writeLiteral(c.Text)
+			fmt.Fprintln(p, ".Ed")
+		case *comment.List:
+			kind := "-bullet"
+			if c.Items[0].Number != "" {
+				kind = "-enum"
+			}
+			fmt.Fprintf(p, ".Bl %s\n", kind)
+			for _, item := range c.Items {
+				fmt.Fprintln(p, ".It")
+				p.writeContent(item.Content, depth+1, false)
+			}
+			fmt.Fprintln(p, ".El")
+		}
+		cont = false
+	}
+}
+
+// writeInline writes a's words as text lines, and its emphases and links as macros.
+func (p *mdocPrinter) writeInline(a []comment.Text) {
+	for i, t := range a {
+		switch t := t.(type) {
+		case comment.Plain:
+			s := string(t)
+			// The quotes around a link are written with Dq macro instead.
+			if quotedLink(a, i-1) {
+				n := len(s) - len(trimClosing(s))
+				s = s[:n] + s[n+1:]
+			}
+			if quotedLink(a, i+1) {
+				s = s[:len(s)-1]
+			}
+			if p.macro != "" {
+				// Closing punctuations are attached to the previous macro.
+				i := len(s) - len(trimClosing(s))
+				for _, c := range s[:i] {
+					p.macro += " " + string(c)
+				}
+				s = s[i:]
+			}
+			p.flushMacro()
+			p.writeText(s)
+		case comment.Italic:
+			p.flushMacro()
+			p.macro = fmt.Sprintf(".Em %q", roff.Str(t))
+		case *comment.Link:
+			p.flushMacro()
+			p.macro = linkMacro(t.URL, t.Text, quotedLink(a, i))
+		case *comment.DocLink:
+			p.flushMacro()
+			p.macro = linkMacro(t.DefaultURL("https://pkg.go.dev"), t.Text, quotedLink(a, i))
+		}
+	}
+	p.flushMacro()
+}
+
+// linkMacro returns Lk macro for the link to url.
+// If quoted is true, the link is enclosed in Dq macro.
+func linkMacro(url string, text []comment.Text, quoted bool) string {
+	s := fmt.Sprintf("Lk %q %q", roff.Str(url), roff.Str(plainText(text)))
+	if quoted {
+		s = "Dq " + s
+	}
+	return "." + s
+}
+
+// quotedLink reports whether a[i] is a link that is enclosed in double quotes by the texts around it.
+func quotedLink(a []comment.Text, i int) bool {
+	if i <= 0 || i+1 >= len(a) {
+		return false
+	}
+	switch a[i].(type) {
+	case *comment.Link, *comment.DocLink:
+	default:
+		return false
+	}
+	prev, ok := a[i-1].(comment.Plain)
+	if !ok || !strings.HasSuffix(string(prev), `"`) {
+		return false
+	}
+	next, ok := a[i+1].(comment.Plain)
+	return ok && strings.HasPrefix(trimClosing(string(next)), `"`)
+}
+
+// trimClosing returns s without leading closing punctuations.
+func trimClosing(s string) string {
+	return strings.TrimLeft(s, ".,:;)]?!")
+}
+
+func (p *mdocPrinter) flushMacro() {
+	if p.macro == "" {
+		return
+	}
+	fmt.Fprintln(p, p.macro)
+	p.macro = ""
+}
+
+// writeText writes s as text lines.
+// Lines that looks like requests are escaped.
+func (p *mdocPrinter) writeText(s string) {
+	for line := range strings.Lines(s) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if line[0] == '.' || line[0] == '\'' {
+			fmt.Fprint(p, `\&`)
+		}
+		fmt.Fprintf(p, "%s\n", roff.Str(line))
+	}
+}
+
+// writeLiteral writes s as it is in a literal display.
+func (p *mdocPrinter) writeLiteral(s string) {
+	for line := range strings.Lines(s) {
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			fmt.Fprint(p, `\&`)
+		}
+		fmt.Fprintf(p, "% s\n", roff.Str(line))
+	}
+}
@@ -0,0 +1,124 @@
+package man
+
+import (
+	"go/doc/comment"
+	"strings"
+	"testing"
+)
+
+func TestMdocOption(t *testing.T) {
+	tests := []struct {
+		flg  *Flag
+		want string
+	}{
+		{
+			flg:  &Flag{Name: "v", Kind: BoolFlag},
+			want: "Fl v",
+		},
+		{
+			flg:  &Flag{Name: "o", Placeholder: "file"},
+			want: "Fl o Ns = Ns Ar file",
+		},
+		{
+			flg:  &Flag{Name: "output", Shorthand: "o", Long: true, Placeholder: "file"},
+			want: `Fl o , Fl \-output Ns = Ns Ar file`,
+		},
+	}
+	for _, tt := range tests {
+		names := []string{"-" + tt.flg.Name}
+		if tt.flg.Long {
+			names = optionNames(tt.flg)
+		}
+		if s := mdocOption(tt.flg, names); s != tt.want {
+			t.Errorf("mdocOption(%v) = %q; want %q", names, s, tt.want)
+		}
+	}
+}
+
+func TestMdocSynopsis(t *testing.T) {
+	tests := []struct {
+		flags []*Flag
+		want  string
+	}{
+		{
+			flags: []*Flag{
+				{Name: "dir", Placeholder: "dir"},
+				{Name: "v", Kind: BoolFlag},
+			},
+			want: ".Sh SYNOPSIS\n.Nm\n.Op Fl dir Ns = Ns Ar dir\n.Op Fl v\n",
+		},
+		{
+			flags: []*Flag{
+				{Name: "output", Shorthand: "o", Long: true, Placeholder: "file"},
+				{Name: "verbose", Long: true, Kind: BoolFlag},
+			},
+			want: ".Sh SYNOPSIS\n.Nm\n.Op Fl o Ns = Ns Ar file\n.Op Fl \\-verbose\n",
+		},
+	}
+	for _, tt := range tests {
+		var buf strings.Builder
+		p := newMdocPrinter(&buf)
+		p.writeSynopsis(&Synopsis{Command: "cmd"}, tt.flags)
+		if s := buf.String(); s != tt.want {
+			t.Errorf("writeSynopsis(%v) = %q; want %q", tt.flags, s, tt.want)
+		}
+	}
+}
+
+func TestMdocSymbols(t *testing.T) {
+	tests := []struct {
+		sym  *Symbol
+		want string
+	}{
+		{
+			sym:  newSymbol(FuncSymbol, "Run", "Run runs the command.\n\nIt returns an error."),
+			want: ".Pp\n.Fn Run\nruns the command.\n.Pp\nIt returns an error.\n",
+		},
+		{
+			sym:  newSymbol(VarSymbol, "Debug", "If true, it writes debug logs."),
+			want: ".Pp\nIf true, it writes debug logs.\n",
+		},
+	}
+	for _, tt := range tests {
+		var buf strings.Builder
+		p := newMdocPrinter(&buf)
+		p.writeSection(&Section{Symbols: []*Symbol{tt.sym}})
+		if s := buf.String(); s != tt.want {
+			t.Errorf("writeSection(%s) = %q; want %q", tt.sym.Name, s, tt.want)
+		}
+	}
+}
+
+func TestMdocLink(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{
+			text: "The [Plan 9] Homepage.",
+			want: "The\n.Lk \"https://9p.io/plan9\" \"Plan 9\"\nHomepage.\n",
+		},
+		{
+			text: "See [Plan 9].",
+			want: "See\n.Lk \"https://9p.io/plan9\" \"Plan 9\" .\n",
+		},
+		{
+			text: `Link can have trailer, like "[Plan 9]."`,
+			want: "Link can have trailer, like\n.Dq Lk \"https://9p.io/plan9\" \"Plan 9\" .\n",
+		},
+		{
+			text: `The "[Plan 9]" Homepage.`,
+			want: "The\n.Dq Lk \"https://9p.io/plan9\" \"Plan 9\"\nHomepage.\n",
+		},
+	}
+	var parser comment.Parser
+	for _, tt := range tests {
+		d := parser.Parse(tt.text + "\n\n[Plan 9]: https://9p.io/plan9\n")
+		var buf strings.Builder
+		p := newMdocPrinter(&buf)
+		p.writeInline(d.Content[0].(*comment.Paragraph).Text)
+		if s := buf.String(); s != tt.want {
+			t.Errorf("writeInline(%q) = %q; want %q", tt.text, s, tt.want)
+		}
+	}
+}
@@ -0,0 +1,609 @@
+package man
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/doc/comment"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"iter"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ManPage is a manual page that is independent of output formats.
+// Printers of each format render it.
+type ManPage struct {
+	// Name is the name of the page, such as "git" or "git-commit".
+	Name    string
+	Section string
+	Kind    PageKind
+
+	// Description is the one-line summary in NAME section.
+	Description string
+
+	// Synopsis is nil if the page has no synopsis.
+	Synopsis *Synopsis
+	Options  []*Flag
+	Sections []*Section
+	SeeAlso  []*Reference
+	Bugs     []string
+
+	// Date is the date of the last change of the source; zero means unknown.
+	Date time.Time
+
+	// Source is the source of the page, such as "godoc2man v1.0.0".
+	// It is shown in the footer.
+	Source string
+
+	// Manual is the title of the manual that the page belongs to, such as "Linux Programmer's Manual".
+	// It is shown in the header.
+	Manual string
+
+	// Version is the version of the module that provides the page, such as "v1.4.0".
+	Version string
+}
+
+// PageKind represents what a manual page describes.
+type PageKind int
+
+const (
+	CommandPage PageKind = iota
+	SubcommandPage
+	LibraryPage
+)
+
+// addVersion appends VERSION section that describes m.Version unless the author wrote it.
+func (m *ManPage) addVersion(modPath string) {
+	if m.Version == "" || findSection(m.Sections, "Version") != nil {
+		return
+	}
+	text := "This page describes version " + m.Version
+	if modPath != "" {
+		text += " of " + modPath
+	}
+	m.Sections = append(m.Sections, &Section{
+		Name: "VERSION",
+		Content: []comment.Block{
+			&comment.Paragraph{Text: []comment.Text{comment.Plain(text + ".")}},
+		},
+	})
+}
+
+// dateLayout is the layout of dates in man(7) and the other formats but mdoc(7).
+const dateLayout = "2006-01-02"
+
+// formatDate returns t in layout, or the empty string if t is zero.
+func formatDate(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+// footer returns non-empty fields of the header and the footer of m.
+// They are shown at the end of the page in formats that don't have headers and footers.
+func (m *ManPage) footer() []string {
+	var a []string
+	for _, s := range []string{m.Manual, m.Source, formatDate(m.Date, dateLayout)} {
+		if s != "" {
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+// Synopsis is the usage of a command, or the declarations of a library.
+type Synopsis struct {
+	// Command is the command line such as "git commit".
+	Command string
+
+	// Args are names of positional arguments that follow the options.
+	Args []string
+
+	// Import is the import path of the library.
+	Import string
+	Consts []string
+	Vars   []string
+	Types  []*TypeDecl
+	Funcs  []string
+}
+
+// TypeDecl is the declaration of a type and its constants, functions and methods.
+type TypeDecl struct {
+	Decl   string
+	Consts []string
+	Funcs  []string
+}
+
+// Section is a section of the manual.
+// It is rendered in the order of Content, Definitions, Symbols and Subsections.
+type Section struct {
+	Name string
+
+	// Authored reports whether the heading of the section is written in the document.
+	Authored bool
+
+	Content     []comment.Block
+	Definitions []*Definition
+	Symbols     []*Symbol
+	Subsections []*Section
+}
+
+// DefinitionKind represents what a definition describes.
+type DefinitionKind int
+
+const (
+	CommandDefinition DefinitionKind = iota
+	EnvDefinition
+	ExitStatusDefinition
+)
+
+// Definition is an item of a definition list.
+type Definition struct {
+	Kind DefinitionKind
+	Term string
+	Doc  string
+
+	// Ref is the page that describes the term in detail.
+	Ref *Reference
+}
+
+// Reference refers to another manual page.
+type Reference struct {
+	Name    string
+	Section string
+
+	// Path is the package path of the page; it determines the file name.
+	Path string
+}
+
+// SymbolKind represents the kind of an exported symbol.
+type SymbolKind int
+
+const (
+	ConstSymbol SymbolKind = iota
+	VarSymbol
+	TypeSymbol
+	FuncSymbol
+)
+
+// Symbol is the document of an exported symbol.
+type Symbol struct {
+	Kind SymbolKind
+	Name string
+
+	// Named reports whether the document begins with Name.
+	// If so, Content continues the sentence that begins with Article and Name.
+	Named   bool
+	Article string
+	Content []comment.Block
+
+	// Doc is the document of the symbol as it is written.
+	Doc string
+}
+
+// newCommandPage returns the manual of the command pkg.
+func newCommandPage(pkg *doc.Package, d *comment.Doc, pkgPath, section string, c *commandInfo) *ManPage {
+	name := path.Base(pkgPath)
+	m := &ManPage{
+		Name:        name,
+		Section:     section,
+		Kind:        CommandPage,
+		Description: synopsis(pkg, name),
+		Synopsis:    &Synopsis{Command: name},
+		Options:     c.Flags,
+	}
+	if len(c.Subcommands) > 0 {
+		m.Synopsis.Args = []string{"command"}
+		s := &Section{Name: "SUBCOMMANDS"}
+		for _, sub := range c.Subcommands {
+			s.Definitions = append(s.Definitions, &Definition{
+				Kind: CommandDefinition,
+				Term: sub.Name,
+				Doc:  sentence(sub.Synopsis),
+				Ref: &Reference{
+					Name:    name + "-" + sub.Name,
+					Section: section,
+					Path:    pkgPath + "-" + sub.Name,
+				},
+			})
+		}
+		m.Sections = append(m.Sections, s)
+	}
+	content := d.Content
+	if strings.TrimSpace(pkg.Doc) == "" {
+		// The command is documented in the sources instead, such as Short and Long of cobra.Command.
+		m.Description = c.Synopsis
+		var parser comment.Parser
+		content = parser.Parse(c.Doc).Content
+	}
+	m.Sections = append(m.Sections, contentSections("OVERVIEW", content)...)
+
+	// The variables that the author didn't mention are appended to the section.
+	env := c.Env
+	if s := findSection(m.Sections, "Environment", "Environment Variables"); s != nil {
+		words := identWords(blocksText(s.Content))
+		env = slices.DeleteFunc(slices.Clone(env), func(e *envVar) bool {
+			return slices.Contains(words, e.Name)
+		})
+		s.Definitions = append(s.Definitions, envDefinitions(env)...)
+		env = nil
+	}
+	if len(c.ExitStatus) > 0 && findSection(m.Sections, "Exit Status") == nil {
+		s := &Section{Name: "EXIT STATUS"}
+		for _, status := range c.ExitStatus {
+			s.Definitions = append(s.Definitions, &Definition{
+				Kind: ExitStatusDefinition,
+				Term: strconv.Itoa(status.Code),
+				Doc:  status.Doc,
+			})
+		}
+		m.Sections = append(m.Sections, s)
+	}
+	if len(env) > 0 {
+		m.Sections = append(m.Sections, &Section{
+			Name:        "ENVIRONMENT",
+			Definitions: envDefinitions(env),
+		})
+	}
+	m.Bugs = noteBodies(pkg.Notes["BUG"])
+	return m
+}
+
+// identWords splits s into the words that consist of letters, digits and underscores.
+// For example, the words of "$GOPATH/bin" are "GOPATH" and "bin", thus "PATH" is not.
+func identWords(s string) []string {
+	return strings.FieldsFunc(s, func(c rune) bool {
+		return c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+}
+
+// sentence returns s that ends with a period, or empty string if s is empty.
+func sentence(s string) string {
+	if s == "" {
+		return ""
+	}
+	return strings.TrimSuffix(s, ".") + "."
+}
+
+func envDefinitions(env []*envVar) []*Definition {
+	a := make([]*Definition, len(env))
+	for i, e := range env {
+		a[i] = &Definition{Kind: EnvDefinition, Term: e.Name, Doc: e.Doc}
+	}
+	return a
+}
+
+// newSubcommandPage returns the manual of sub that is a subcommand of pkg.
+// The description is taken from the section of d that has a heading named sub.
+func newSubcommandPage(pkg *doc.Package, d *comment.Doc, pkgPath, section string, sub *subcommand) *ManPage {
+	name := path.Base(pkgPath)
+	parent := path.Base(pkg.ImportPath)
+	m := &ManPage{
+		Name:     name,
+		Section:  section,
+		Kind:     SubcommandPage,
+		Synopsis: &Synopsis{Command: parent + " " + sub.Name},
+		Options:  sub.Flags,
+		SeeAlso: []*Reference{
+			{Name: parent, Section: section, Path: pkg.ImportPath},
+		},
+	}
+	if sub.Synopsis != "" {
+		m.Description = sub.Synopsis
+	} else {
+		m.Description = sub.Name + " subcommand of " + parent
+	}
+	var parser comment.Parser
+	content := parser.Parse(sub.Doc).Content
+	content = append(content, sectionContent(d, sub.Name, parent+" "+sub.Name)...)
+	if len(content) > 0 {
+		m.Sections = append(m.Sections, &Section{Name: "DESCRIPTION", Content: content})
+	}
+	return m
+}
+
+// newLibraryPage returns the manual of the library pkg.
+func newLibraryPage(fset *token.FileSet, pkg *doc.Package, d *comment.Doc, pkgPath, section string) (*ManPage, error) {
+	name := path.Base(pkgPath)
+	m := &ManPage{
+		Name:        name,
+		Section:     section,
+		Kind:        LibraryPage,
+		Description: synopsis(pkg, name),
+		Synopsis:    &Synopsis{Import: pkgPath},
+	}
+	for _, c := range pkg.Consts {
+		s, err := formatDecl(fset, c.Decl)
+		if err != nil {
+			return nil, err
+		}
+		m.Synopsis.Consts = append(m.Synopsis.Consts, s)
+	}
+	for _, v := range pkg.Vars {
+		s, err := formatDecl(fset, v.Decl)
+		if err != nil {
+			return nil, err
+		}
+		m.Synopsis.Vars = append(m.Synopsis.Vars, s)
+	}
+	for _, t := range pkg.Types {
+		s, err := formatDecl(fset, t.Decl)
+		if err != nil {
+			return nil, err
+		}
+		decl := &TypeDecl{Decl: s}
+		for _, c := range t.Consts {
+			s, err := formatDecl(fset, c.Decl)
+			if err != nil {
+				return nil, err
+			}
+			decl.Consts = append(decl.Consts, s)
+		}
+		for f := range mergeSlice(t.Funcs, t.Methods) {
+			s, err := formatDecl(fset, funcDecl(f))
+			if err != nil {
+				return nil, err
+			}
+			decl.Funcs = append(decl.Funcs, s)
+		}
+		m.Synopsis.Types = append(m.Synopsis.Types, decl)
+	}
+	for _, f := range pkg.Funcs {
+		s, err := formatDecl(fset, funcDecl(f))
+		if err != nil {
+			return nil, err
+		}
+		m.Synopsis.Funcs = append(m.Synopsis.Funcs, s)
+	}
+
+	m.Sections = contentSections("DESCRIPTION", d.Content)
+	s := m.Sections[len(m.Sections)-1]
+	consts := slices.Clone(pkg.Consts)
+	for _, t := range pkg.Types {
+		// Typed constants are attached to their types.
+		consts = append(consts, t.Consts...)
+	}
+	if len(consts) > 0 {
+		sub := &Section{Name: "Constants"}
+		for _, c := range consts {
+			sym := newSymbol(ConstSymbol, c.Names[0], c.Doc)
+			if len(c.Names) > 1 {
+				// The document of a group rarely describes each constant;
+				// the declaration shows their values and comments.
+				s, err := formatValues(fset, c.Decl)
+				if err != nil {
+					return nil, err
+				}
+				sym.Content = append(sym.Content, &comment.Code{Text: s})
+			}
+			sub.Symbols = append(sub.Symbols, sym)
+		}
+		s.Subsections = append(s.Subsections, sub)
+	}
+	if len(pkg.Vars) > 0 {
+		sub := &Section{Name: "Variables"}
+		for _, v := range pkg.Vars {
+			sub.Symbols = append(sub.Symbols, newSymbol(VarSymbol, v.Names[0], v.Doc))
+		}
+		s.Subsections = append(s.Subsections, sub)
+	}
+	if len(pkg.Types) > 0 {
+		sub := &Section{Name: "Types"}
+		for _, t := range pkg.Types {
+			sub.Symbols = append(sub.Symbols, newSymbol(TypeSymbol, t.Name, t.Doc))
+		}
+		s.Subsections = append(s.Subsections, sub)
+	}
+	if len(pkg.Funcs) > 0 {
+		sub := &Section{Name: "Functions"}
+		for _, f := range pkg.Funcs {
+			sub.Symbols = append(sub.Symbols, newSymbol(FuncSymbol, f.Name, f.Doc))
+		}
+		s.Subsections = append(s.Subsections, sub)
+	}
+	m.Bugs = noteBodies(pkg.Notes["BUG"])
+	return m, nil
+}
+
+func newSymbol(kind SymbolKind, name, s string) *Symbol {
+	sym := &Symbol{Kind: kind, Name: name, Doc: s}
+	if article, rest, ok := hasPrefix(s, name); ok {
+		sym.Named = true
+		sym.Article = article
+		s = rest
+	}
+	var parser comment.Parser
+	sym.Content = parser.Parse(strings.TrimSpace(s)).Content
+	return sym
+}
+
+func formatDecl(fset *token.FileSet, decl ast.Decl) (string, error) {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, decl); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// formatValues returns the declaration of constants or variables with the comments of each spec.
+func formatValues(fset *token.FileSet, decl *ast.GenDecl) (string, error) {
+	var comments []*ast.CommentGroup
+	for _, spec := range decl.Specs {
+		v := spec.(*ast.ValueSpec)
+		if v.Doc != nil {
+			comments = append(comments, v.Doc)
+		}
+		if v.Comment != nil {
+			comments = append(comments, v.Comment)
+		}
+	}
+	var buf bytes.Buffer
+	node := &printer.CommentedNode{Node: decl, Comments: comments}
+	if err := format.Node(&buf, fset, node); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// funcDecl returns the declaration of f without its body.
+func funcDecl(f *doc.Func) *ast.FuncDecl {
+	x := *f.Decl
+	x.Body = nil
+	return &x
+}
+
+func noteBodies(a []*doc.Note) []string {
+	var bodies []string
+	for _, n := range a {
+		bodies = append(bodies, n.Body)
+	}
+	return bodies
+}
+
+// contentSections splits content into sections by its headings.
+// The blocks before the first heading belong to the section named first.
+func contentSections(first string, content []comment.Block) []*Section {
+	sections := []*Section{{Name: first}}
+	for _, c := range content {
+		if h, ok := c.(*comment.Heading); ok {
+			sections = append(sections, &Section{Name: plainText(h.Text), Authored: true})
+			continue
+		}
+		s := sections[len(sections)-1]
+		s.Content = append(s.Content, c)
+	}
+	return sections
+}
+
+// sectionTitle returns the name of s in Markdown and HTML, that call the overview of commands DESCRIPTION.
+func sectionTitle(s *Section) string {
+	if !s.Authored && s.Name == "OVERVIEW" {
+		return "DESCRIPTION"
+	}
+	return s.Name
+}
+
+// findSection returns the section that has the name of any of names.
+func findSection(sections []*Section, names ...string) *Section {
+	for _, s := range sections {
+		if slices.ContainsFunc(names, func(name string) bool {
+			return strings.EqualFold(s.Name, name)
+		}) {
+			return s
+		}
+	}
+	return nil
+}
+
+// synopsis returns the synopsis of pkg without leading name.
+func synopsis(pkg *doc.Package, name string) string {
+	s := pkg.Synopsis(pkg.Doc)
+	s = strings.TrimPrefix(s, name)
+	return strings.TrimSpace(s)
+}
+
+// sectionContent returns the blocks under the heading that matches any of names.
+func sectionContent(d *comment.Doc, names ...string) []comment.Block {
+	i, j := sectionRange(d.Content, names...)
+	if i < 0 {
+		return nil
+	}
+	return d.Content[i+1 : j]
+}
+
+// sectionRange returns the index of the heading that matches any of names,
+// and the index of the next heading or len(content).
+// It returns -1 if there is no such heading.
+func sectionRange(content []comment.Block, names ...string) (int, int) {
+	for i, c := range content {
+		h, ok := c.(*comment.Heading)
+		if !ok || !slices.ContainsFunc(names, func(s string) bool {
+			return strings.EqualFold(plainText(h.Text), s)
+		}) {
+			continue
+		}
+		for j, c := range content[i+1:] {
+			if _, ok := c.(*comment.Heading); ok {
+				return i, i + 1 + j
+			}
+		}
+		return i, len(content)
+	}
+	return -1, -1
+}
+
+// blocksText returns the plain text of content.
+func blocksText(content []comment.Block) string {
+	var b strings.Builder
+	for _, c := range content {
+		switch c := c.(type) {
+		case *comment.Heading:
+			b.WriteString(plainText(c.Text))
+		case *comment.Paragraph:
+			b.WriteString(plainText(c.Text))
+		case *comment.Code:
+			b.WriteString(c.Text)
+		case *comment.List:
+			for _, item := range c.Items {
+				b.WriteString(blocksText(item.Content))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func plainText(a []comment.Text) string {
+	var b strings.Builder
+	for _, t := range a {
+		switch t := t.(type) {
+		case comment.Plain:
+			b.WriteString(string(t))
+		case comment.Italic:
+			b.WriteString(string(t))
+		case *comment.Link:
+			b.WriteString(plainText(t.Text))
+		case *comment.DocLink:
+			b.WriteString(plainText(t.Text))
+		}
+	}
+	return b.String()
+}
+
+func mergeSlice[S ~[]E, E any](s ...S) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for _, a := range s {
+			for _, v := range a {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func hasPrefix(s, name string) (before, rest string, ok bool) {
+	switch {
+	case strings.HasPrefix(s, "The "):
+		before = "The"
+		s = s[4:]
+	case strings.HasPrefix(s, "An "):
+		before = "An"
+		s = s[3:]
+	case strings.HasPrefix(s, "A "):
+		before = "A"
+		s = s[2:]
+	}
+	if strings.HasPrefix(s, name+" ") {
+		return before, s[len(name)+1:], true
+	}
+	return "", "", false
+}
@@ -0,0 +1,165 @@
+package man
+
+import (
+	"go/doc"
+	"go/doc/comment"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContentSections(t *testing.T) {
+	var parser comment.Parser
+	d := parser.Parse("overview\n\n# Environment\n\nHOME is used.\n\n# Bugs\n\nmany bugs")
+	sections := contentSections("OVERVIEW", d.Content)
+	var names []string
+	for _, s := range sections {
+		names = append(names, s.Name)
+	}
+	want := []string{"OVERVIEW", "Environment", "Bugs"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("contentSections() = %v; want %v", names, want)
+	}
+}
+
+func TestNewCommandPage(t *testing.T) {
+	var parser comment.Parser
+	d := parser.Parse("cmd is a command.\n\n# Environment\n\nHOME is the base directory.")
+	c := &commandInfo{
+		Env: []*envVar{
+			{Name: "HOME"},
+			{Name: "EDITOR", Doc: "editor to use"},
+		},
+		ExitStatus: []*exitStatus{
+			{Code: 0, Doc: "Successful completion."},
+		},
+	}
+	m := newCommandPage(&doc.Package{Doc: "cmd is a command."}, d, "example.com/cmd", "1", c)
+	if m.Name != "cmd" || m.Description != "is a command." {
+		t.Errorf("newCommandPage() = {Name: %q, Description: %q}; want {cmd, is a command.}", m.Name, m.Description)
+	}
+	var names []string
+	for _, s := range m.Sections {
+		names = append(names, s.Name)
+	}
+	if want := []string{"OVERVIEW", "Environment", "EXIT STATUS"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Sections = %v; want %v", names, want)
+	}
+	env := m.Sections[1].Definitions
+	want := []*Definition{{Kind: EnvDefinition, Term: "EDITOR", Doc: "editor to use"}}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("Definitions of Environment = %v; want %v", env, want)
+	}
+}
+
+func TestNewCommandPageEnvWords(t *testing.T) {
+	var parser comment.Parser
+	d := parser.Parse("cmd is a command.\n\n# Environment\n\nThe binaries are installed into $GOPATH/bin.")
+	c := &commandInfo{
+		Env: []*envVar{
+			{Name: "GOPATH"},
+			{Name: "PATH", Doc: "directories to search the commands"},
+		},
+	}
+	m := newCommandPage(&doc.Package{Doc: "cmd is a command."}, d, "example.com/cmd", "1", c)
+	s := findSection(m.Sections, "Environment")
+	if s == nil {
+		t.Fatalf("newCommandPage() doesn't have Environment section")
+	}
+	want := []*Definition{{Kind: EnvDefinition, Term: "PATH", Doc: "directories to search the commands"}}
+	if !reflect.DeepEqual(s.Definitions, want) {
+		t.Errorf("Definitions of Environment = %v; want %v", s.Definitions, want)
+	}
+}
+
+func TestNewCommandPageCommandDoc(t *testing.T) {
+	c := &commandInfo{
+		Synopsis: "run commands",
+		Doc:      "Cmd runs the commands.",
+	}
+	m := newCommandPage(&doc.Package{}, &comment.Doc{}, "example.com/cmd", "1", c)
+	if m.Description != "run commands" {
+		t.Errorf("Description = %q; want %q", m.Description, "run commands")
+	}
+	if len(m.Sections) != 1 || m.Sections[0].Name != "OVERVIEW" {
+		t.Fatalf("Sections = %v; want [OVERVIEW]", m.Sections)
+	}
+	want := []comment.Block{&comment.Paragraph{Text: []comment.Text{comment.Plain("Cmd runs the commands.")}}}
+	if !reflect.DeepEqual(m.Sections[0].Content, want) {
+		t.Errorf("Content of OVERVIEW = %v; want %v", m.Sections[0].Content, want)
+	}
+
+	// The package document is preferred to the command's.
+	var parser comment.Parser
+	m = newCommandPage(&doc.Package{Doc: "cmd is a command."}, parser.Parse("cmd is a command."), "example.com/cmd", "1", c)
+	if m.Description != "is a command." {
+		t.Errorf("Description = %q; want %q", m.Description, "is a command.")
+	}
+}
+
+func TestNewLibraryPageConsts(t *testing.T) {
+	pkg := loadTestPackage(t, ".", "./testdata/consts")
+	p, err := doc.NewFromFiles(pkg.Fset, pkg.Syntax, pkg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parser comment.Parser
+	m, err := newLibraryPage(pkg.Fset, p, parser.Parse(p.Doc), pkg.ID, "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"const MaxSize = 1024"}; !reflect.DeepEqual(m.Synopsis.Consts, want) {
+		t.Errorf("Synopsis.Consts = %q; want %q", m.Synopsis.Consts, want)
+	}
+	if n := len(m.Synopsis.Types[0].Consts); n != 1 {
+		t.Errorf("Synopsis.Types[0].Consts has %d groups; want 1", n)
+	}
+	s := findSection(m.Sections[0].Subsections, "Constants")
+	if s == nil {
+		t.Fatalf("Constants subsection is not found")
+	}
+	var names []string
+	for _, sym := range s.Symbols {
+		names = append(names, sym.Name)
+	}
+	if want := []string{"MaxSize", "ReadOnly"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Constants = %v; want %v", names, want)
+	}
+	content := s.Symbols[1].Content
+	code, ok := content[len(content)-1].(*comment.Code)
+	if !ok {
+		t.Fatalf("the document of ReadOnly group doesn't end with the declaration: %v", content)
+	}
+	want := `const (
+	ReadOnly  Mode = iota // open the file read-only
+	WriteOnly             // open the file write-only
+)`
+	if code.Text != want {
+		t.Errorf("the declaration of ReadOnly group = %q; want %q", code.Text, want)
+	}
+}
+
+func TestAddVersion(t *testing.T) {
+	m := &ManPage{Name: "cmd"}
+	m.addVersion("example.com/m")
+	if len(m.Sections) != 0 {
+		t.Errorf("addVersion(unknown version) appends %d sections; want 0", len(m.Sections))
+	}
+
+	m.Version = "v1.4.0"
+	m.addVersion("example.com/m")
+	s := findSection(m.Sections, "VERSION")
+	if s == nil {
+		t.Fatalf("addVersion doesn't append VERSION section")
+	}
+	want := "This page describes version v1.4.0 of example.com/m."
+	if text := strings.TrimSpace(blocksText(s.Content)); text != want {
+		t.Errorf("VERSION = %q; want %q", text, want)
+	}
+
+	m.Version = "v1.5.0"
+	m.addVersion("example.com/m")
+	if len(m.Sections) != 1 {
+		t.Errorf("addVersion appends VERSION section twice")
+	}
+}
@@ -0,0 +1,167 @@
+package man
+
+import (
+	"flag"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const pflagPath = "github.com/spf13/pflag"
+
+var pflagFlagSet = &flagSetKind{
+	isSet: isPFlagSet,
+	origin: func(s *flagSets, x ast.Expr) (string, bool) {
+		return newPFlagSetName(s.info, x)
+	},
+}
+
+// findPFlags retrieves flags defined with github.com/spf13/pflag package.
+// Arguments that are not constants are reported to warn unless it is nil.
+func findPFlags(info *types.Info, fset *token.FileSet, files []*ast.File, warn func(msg string)) []*Flag {
+	return collectPFlags(pflagFlagSet, info, fset, files, warn)
+}
+
+// collectPFlags retrieves flags defined with pflag functions, and names their flag sets as kind does.
+func collectPFlags(kind *flagSetKind, info *types.Info, fset *token.FileSet, files []*ast.File, warn func(msg string)) []*Flag {
+	var flags []*Flag
+	sets := newFlagSets(kind, info, files)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			ast.Inspect(decl, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				if flg := pflagFunc(info, fset, call, warn); flg != nil {
+					flg.FlagSet = flagSetName(sets, call)
+					flags = append(flags, flg)
+				}
+				return true
+			})
+		}
+	}
+	return flags
+}
+
+// pflagFunc returns the flag that call defines.
+// Because every function of pflag names its parameters consistently,
+// the arguments are taken by the parameter name.
+func pflagFunc(info *types.Info, fset *token.FileSet, call *ast.CallExpr, warn func(msg string)) *Flag {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || !isPFlagFunc(fn) {
+		return nil
+	}
+	params := fn.Signature().Params()
+	if params.Len() != len(call.Args) {
+		return nil
+	}
+	args := make(map[string]ast.Expr)
+	for i := range params.Len() {
+		args[params.At(i).Name()] = call.Args[i]
+	}
+	if args["name"] == nil || args["usage"] == nil {
+		return nil
+	}
+	_, hasShort := args["shorthand"]
+	typ := pflagType(fn.Name(), hasShort)
+	flg := flag.Flag{
+		Name:  argStr(info, fset, args["name"], "name", warn),
+		Usage: argStr(info, fset, args["usage"], "usage", warn),
+	}
+	// Var and its variants take pflag.Value as value instead of the default value.
+	switch typ {
+	case "":
+	case "text":
+		flg.DefValue = textStr(info, args["value"])
+	default:
+		flg.DefValue = argStr(info, fset, args["value"], "default value", warn)
+	}
+	short := argStr(info, fset, args["shorthand"], "shorthand", warn)
+	name, usage := flag.UnquoteUsage(&flg)
+	if !hasVarName(flg.Usage) {
+		name = pflagTypeName(typ)
+	}
+	return &Flag{
+		Name:        flg.Name,
+		Shorthand:   short,
+		Long:        true,
+		Kind:        switchKind(typ),
+		Placeholder: name,
+		Usage:       usage,
+		Type:        typ,
+		Default:     nonZero(typ, flg.DefValue),
+	}
+}
+
+// pflagType returns the type name of the flag defined by the function fname.
+// The name is same as pflag.Value.Type returns.
+// If the function takes a shorthand, its name has the suffix "P" such as IntP or IPVarP;
+// the other names, such as IP, end with the type name itself.
+func pflagType(fname string, shorthand bool) string {
+	s := strings.TrimSuffix(fname, "F")
+	if shorthand {
+		s = strings.TrimSuffix(s, "P")
+	}
+	s = strings.TrimSuffix(s, "Var")
+	return lowerCamel(s)
+}
+
+// pflagTypeName returns the placeholder for the flag of typ.
+// The name is same as pflag.UnquoteUsage returns.
+func pflagTypeName(typ string) string {
+	switch typ {
+	case "", "func":
+		return "value"
+	case "bool", "boolFunc", "count":
+		return ""
+	case "float64":
+		return "float"
+	case "int64":
+		return "int"
+	case "uint64":
+		return "uint"
+	case "stringSlice":
+		return "strings"
+	case "intSlice":
+		return "ints"
+	case "uintSlice":
+		return "uints"
+	case "boolSlice":
+		return "bools"
+	}
+	return typ
+}
+
+// isPFlagFunc reports whether fn is a function of pflag package or a method of pflag.FlagSet.
+func isPFlagFunc(fn *types.Func) bool {
+	if fn.Pkg() == nil || fn.Pkg().Path() != pflagPath {
+		return false
+	}
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		return true
+	}
+	return isPFlagSet(recv.Type())
+}
+
+// isPFlagSet reports whether t is pflag.FlagSet or a pointer to it.
+func isPFlagSet(t types.Type) bool {
+	return isNamed(t, pflagPath, "FlagSet")
+}
+
+// newPFlagSetName returns the name of the FlagSet if x calls pflag.NewFlagSet.
+func newPFlagSetName(info *types.Info, x ast.Expr) (string, bool) {
+	call, ok := x.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || !isPFlagFunc(fn) || fn.Name() != "NewFlagSet" || len(call.Args) != 2 {
+		return "", false
+	}
+	return exprStr(info, call.Args[0]), true
+}
@@ -0,0 +1,408 @@
+package man
+
+import (
+	"fmt"
+	"go/doc/comment"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/lufia/godoc2man/internal/roff"
+)
+
+// manPrinter writes manuals with man(7) macros.
+type manPrinter struct {
+	w   io.Writer
+	err error
+}
+
+func newManPrinter(w io.Writer) *manPrinter {
+	return &manPrinter{w, nil}
+}
+
+func (p *manPrinter) Err() error {
+	return p.err
+}
+
+// commandInfo is the information of a command retrieved from its sources.
+type commandInfo struct {
+	Flags       []*Flag
+	Subcommands []*subcommand
+	Env         []*envVar
+	ExitStatus  []*exitStatus
+
+	// Synopsis and Doc are the short and long description of the command.
+	// They are set only if the flag package provides them, like cobra.
+	Synopsis string
+	Doc      string
+}
+
+func (p *manPrinter) Page(m *ManPage) {
+	fmt.Fprintf(p, ".TH %s %s", m.Name, m.Section)
+	fields := []string{m.Source, m.Manual}
+	for len(fields) > 0 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	if date := formatDate(m.Date, dateLayout); date != "" || len(fields) > 0 {
+		// The date consists of digits and hyphens that should not be escaped.
+		fmt.Fprintf(p, ` "%s"`, date)
+	}
+	for _, s := range fields {
+		fmt.Fprintf(p, " %q", roff.Str(s))
+	}
+	fmt.Fprintln(p, "")
+	fmt.Fprintln(p, ".SH NAME")
+	if m.Kind == SubcommandPage {
+		fmt.Fprintf(p, "%s \\- %s\n", m.Name, roff.Str(m.Description))
+	} else {
+		// The synopses of commands and libraries are written without escapes.
+		fmt.Fprintf(p, "%s \\- %s\n", m.Name, m.Description)
+	}
+	// Pages of commands have been written without SYNOPSIS section;
+	// authors write the usage in the document instead.
+	if m.Kind != CommandPage {
+		p.writeSynopsis(m.Synopsis, len(m.Options) > 0)
+	}
+	p.writeOptions(m.Options)
+	for i, s := range m.Sections {
+		w := newHeadingWriter(p)
+		fmt.Fprintf(w, ".SH %s", roff.Str(s.Name))
+		fmt.Fprintln(p, "")
+		if m.Kind == LibraryPage && i == len(m.Sections)-1 {
+			s = withSymbolSections(s)
+		}
+		p.writeSection(s)
+	}
+	if len(m.SeeAlso) > 0 {
+		fmt.Fprintln(p, ".SH SEE ALSO")
+		for i, ref := range m.SeeAlso {
+			if i > 0 {
+				fmt.Fprintln(p, ",")
+			}
+			fmt.Fprintf(p, ".BR %s (%s)", roff.Str(ref.Name), ref.Section)
+		}
+		fmt.Fprintln(p, "")
+	}
+	p.writeBugs(m.Bugs)
+}
+
+func (p *manPrinter) writeSynopsis(s *Synopsis, hasOptions bool) {
+	if s == nil {
+		return
+	}
+	fmt.Fprintln(p, ".SH SYNOPSIS")
+	if s.Import == "" {
+		fmt.Fprintf(p, ".B \"%s\"\n", roff.Str(s.Command))
+		if hasOptions {
+			fmt.Fprintln(p, "[\\fIoptions\\fR]")
+		}
+		for _, arg := range s.Args {
+			fmt.Fprintf(p, "\\fI%s\\fR\n", roff.Str(arg))
+		}
+		return
+	}
+	fmt.Fprintln(p, ".nf")
+	fmt.Fprintf(p, ".B \"import \\(dq%s\\(dq\"\n", s.Import)
+	fmt.Fprintln(p, ".sp")
+	for _, c := range s.Consts {
+		fmt.Fprintf(p, "%s\n", c)
+	}
+	if len(s.Consts) > 0 && len(s.Vars) > 0 {
+		fmt.Fprint(p, "\n")
+	}
+	for _, v := range s.Vars {
+		fmt.Fprintf(p, "%s\n", v)
+	}
+	ndef := len(s.Consts) + len(s.Vars)
+	if ndef > 0 && len(s.Types) > 0 {
+		fmt.Fprint(p, "\n")
+		ndef = 0
+	}
+	ndef += len(s.Types)
+	for _, t := range s.Types {
+		fmt.Fprintf(p, "%s\n", t.Decl)
+		for _, c := range t.Consts {
+			fmt.Fprintf(p, "%s\n", c)
+		}
+		for _, f := range t.Funcs {
+			p.writeFunc(f)
+		}
+		fmt.Fprint(p, "\n")
+	}
+	if ndef > 0 && len(s.Funcs) > 0 {
+		fmt.Fprint(p, "\n")
+	}
+	for _, f := range s.Funcs {
+		p.writeFunc(f)
+	}
+	fmt.Fprintln(p, ".fi")
+}
+
+func (p *manPrinter) writeFunc(decl string) {
+	fmt.Fprintf(p, ".BI \"%s\\\"\n", decl)
+}
+
+func (p *manPrinter) writeSection(s *Section) {
+	p.writeContent(s.Content, 0, false)
+	for _, d := range s.Definitions {
+		fmt.Fprintln(p, ".TP")
+		fmt.Fprintf(p, ".B %s\n", roff.Str(d.Term))
+		switch {
+		case d.Doc == "":
+		case d.Kind == CommandDefinition:
+			fmt.Fprintf(p, "%s\n", roff.Str(d.Doc))
+		default:
+			fmt.Fprintf(p, "%s\n", roff.Str(strings.Join(strings.Fields(d.Doc), " ")))
+		}
+		if d.Ref != nil {
+			fmt.Fprintf(p, "See\n.BR %s (%s).\n", roff.Str(d.Ref.Name), d.Ref.Section)
+		}
+	}
+	for _, sym := range s.Symbols {
+		p.writeSymbol(sym)
+	}
+	for _, sub := range s.Subsections {
+		if len(sub.Symbols) > 0 {
+			fmt.Fprintln(p, ".PP")
+		}
+		fmt.Fprintf(p, ".SS %s\n", roff.Str(sub.Name))
+		p.writeSection(sub)
+	}
+}
+
+// withSymbolSections returns s that has the subsections of variables, types and functions even if they are empty.
+func withSymbolSections(s *Section) *Section {
+	x := *s
+	x.Subsections = nil
+	if sub := findSection(s.Subsections, "Constants"); sub != nil {
+		x.Subsections = append(x.Subsections, sub)
+	}
+	for _, name := range []string{"Variables", "Types", "Functions"} {
+		sub := findSection(s.Subsections, name)
+		if sub == nil {
+			sub = &Section{Name: name}
+		}
+		x.Subsections = append(x.Subsections, sub)
+	}
+	return &x
+}
+
+func (p *manPrinter) writeSymbol(sym *Symbol) {
+	if sym.Kind == FuncSymbol {
+		s := sym.Doc
+		if strings.HasPrefix(s, sym.Name) {
+			fmt.Fprintln(p, ".BR", sym.Name, "()")
+			s = s[len(sym.Name):]
+		}
+		var parser comment.Parser
+		p.writeContent(parser.Parse(strings.TrimSpace(s)).Content, 0, true)
+		fmt.Fprintln(p, ".PP")
+		return
+	}
+	if sym.Named {
+		fmt.Fprintln(p, sym.Article)
+		fmt.Fprintln(p, ".BR", sym.Name)
+	}
+	p.writeContent(sym.Content, 0, true)
+	fmt.Fprint(p, "\n")
+}
+
+// optDef and swDef define the macros for the options that take a value and the switches.
+var (
+	optDef = strings.TrimSpace(`
+.de OPT
+.TP
+\fB\-\\$1\fR=\fI\\$2\fR
+.shift 2
+\\$*
+..
+`)
+	swDef = strings.TrimSpace(`
+.de SW
+.TP
+\fB\-\\$1\fR
+.shift 1
+\\$*
+..
+`)
+)
+
+func (p *manPrinter) writeOptions(flags []*Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintln(p, ".SH OPTIONS")
+	// GNU-style options are written without the macros.
+	if slices.ContainsFunc(flags, func(flg *Flag) bool { return !flg.Long && !flg.Kind.IsSwitch() }) {
+		fmt.Fprintln(p, optDef)
+	}
+	if slices.ContainsFunc(flags, func(flg *Flag) bool { return !flg.Long && flg.Kind.IsSwitch() }) {
+		fmt.Fprintln(p, swDef)
+	}
+	for _, flg := range flags {
+		if flg.Long {
+			p.writeLongOption(flg)
+			continue
+		}
+		def := fmt.Sprintf("% s", roff.Str(defaultText(flg)))
+		if flg.Kind.IsSwitch() {
+			fmt.Fprintln(p, ".SW", flg.Name, flg.Usage+def)
+			continue
+		}
+		fmt.Fprintln(p, ".OPT", flg.Name, strings.ToUpper(flg.Placeholder), flg.Usage+def)
+	}
+}
+
+// writeLongOption writes GNU-style option such as "-v, --verbose".
+func (p *manPrinter) writeLongOption(flg *Flag) {
+	fmt.Fprintln(p, ".TP")
+	for i, name := range optionNames(flg) {
+		if i > 0 {
+			fmt.Fprint(p, ", ")
+		}
+		fmt.Fprintf(p, "\\fB%s\\fR", roff.Str(name))
+	}
+	if flg.Placeholder != "" {
+		fmt.Fprintf(p, "=\\fI%s\\fR", roff.Str(strings.ToUpper(flg.Placeholder)))
+	}
+	fmt.Fprintln(p, "")
+	fmt.Fprintf(p, "%s\n", roff.Str(flg.Usage+defaultText(flg)))
+	if len(flg.Env) > 0 {
+		a := make([]string, len(flg.Env))
+		for i, s := range flg.Env {
+			a[i] = "$" + s
+		}
+		fmt.Fprintf(p, "[%s]\n", roff.Str(strings.Join(a, ", ")))
+	}
+}
+
+// defaultText returns the annotation for the default value of flg like flag.PrintDefaults.
+func defaultText(flg *Flag) string {
+	switch {
+	case flg.Default == "":
+		return ""
+	case flg.Type == "string":
+		return fmt.Sprintf(" (default %q)", flg.Default)
+	default:
+		return fmt.Sprintf(" (default %s)", flg.Default)
+	}
+}
+
+// optionNames returns names of GNU-style option flg with leading dashes.
+// The shorthand comes first, then the name and its aliases follow.
+func optionNames(flg *Flag) []string {
+	var a []string
+	if flg.Shorthand != "" {
+		a = append(a, "-"+flg.Shorthand)
+	}
+	for _, name := range append([]string{flg.Name}, flg.Aliases...) {
+		if len(name) == 1 {
+			a = append(a, "-"+name)
+		} else {
+			a = append(a, "--"+name)
+		}
+	}
+	return a
+}
+
+func (p *manPrinter) writeContent(content []comment.Block, depth int, cont bool) {
+	for _, c := range content {
+		switch c := c.(type) {
+		case *comment.Heading:
+			w := newHeadingWriter(p)
+			fmt.Fprintf(w, ".SH %s", roffText(c.Text))
+			fmt.Fprintln(p, "")
+		case *comment.Paragraph:
+			if depth == 0 && !cont {
+				fmt.Fprintln(p, ".PP")
+			}
+			fmt.Fprintf(p, "%+s", roffText(c.Text))
+		case *comment.Code:
+			fmt.Fprintln(p, ".PP")
+			fmt.Fprintln(p, ".EX")
+			fmt.Fprintln(p, ".in +4n")
+			fmt.Fprintf(p, "%s\n", roff.Str(c.Text))
+			fmt.Fprintln(p, ".in")
+			fmt.Fprintln(p, ".EE")
+		case *comment.List:
+			for _, item := range c.Items {
+				symbol := roff.Bullet
+				if item.Number != "" {
+					symbol = item.Number + "."
+				}
+				fmt.Fprintf(p, ".IP %s 4\n", symbol)
+				p.writeContent(item.Content, depth+1, false)
+			}
+		}
+		cont = false
+	}
+}
+
+func (p *manPrinter) writeBugs(a []string) {
+	if len(a) == 0 {
+		return
+	}
+	fmt.Fprintln(p, ".SH BUGS")
+	for _, s := range a {
+		fmt.Fprintln(p, ".PP")
+		fmt.Fprintln(p, s)
+	}
+}
+
+func (p *manPrinter) Write(data []byte) (n int, err error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.w.Write(data)
+}
+
+type roffText []comment.Text
+
+func (t roffText) Format(f fmt.State, c rune) {
+	w := newExpWriter(f)
+	format := "%"
+	if f.Flag('+') {
+		format += "+"
+	}
+	format += string(c)
+
+	trailing := false
+	for _, v := range t {
+		switch v := v.(type) {
+		case comment.Plain:
+			if trailing {
+				if strings.HasPrefix(string(v), " ") {
+					fmt.Fprint(w, "\n")
+				} else {
+					fmt.Fprint(w, " ")
+				}
+				trailing = false
+			}
+			fmt.Fprintf(w, "%s\n", roff.Str(v))
+		case comment.Italic:
+			if f.Flag('+') {
+				fmt.Fprintf(w, ".I ")
+			}
+			fmt.Fprintf(w, "%s\n", roff.Str(v))
+		case *comment.Link:
+			if f.Flag('+') {
+				fmt.Fprintf(w, ".UR %q\n", roff.Str(v.URL))
+			}
+			fmt.Fprintf(w, format, roffText(v.Text))
+			if f.Flag('+') {
+				fmt.Fprintf(w, ".UE")
+				trailing = true
+			}
+		case *comment.DocLink:
+			if f.Flag('+') {
+				u := v.DefaultURL("https://pkg.go.dev")
+				fmt.Fprintf(w, "\n.UR %q\n", roff.Str(u))
+			}
+			fmt.Fprintf(w, format, roffText(v.Text))
+			if f.Flag('+') {
+				fmt.Fprintf(w, ".UE")
+				trailing = true
+			}
+		}
+	}
+}
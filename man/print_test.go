@@ -0,0 +1,83 @@
+package man
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrinterWrite(t *testing.T) {
+	var buf strings.Builder
+	p := newManPrinter(&buf)
+	s := "test"
+	fmt.Fprintf(p, "%s", s)
+	if v := buf.String(); v != s {
+		t.Errorf("Write() = %q; want %q", v, s)
+	}
+}
+
+func TestPrinterTitle(t *testing.T) {
+	date := time.Date(2023, 11, 14, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		m    *ManPage
+		want string
+	}{
+		{&ManPage{Name: "cmd", Section: "1"}, ".TH cmd 1\n"},
+		{&ManPage{Name: "cmd", Section: "1", Date: date}, `.TH cmd 1 "2023-11-14"` + "\n"},
+		{&ManPage{Name: "cmd", Section: "1", Manual: "My Manual"}, `.TH cmd 1 "" "" "My Manual"` + "\n"},
+		{&ManPage{Name: "cmd", Section: "1", Date: date, Source: "my-proj"}, `.TH cmd 1 "2023-11-14" "my\-proj"` + "\n"},
+	}
+	for _, tt := range tests {
+		var buf strings.Builder
+		p := newManPrinter(&buf)
+		p.Page(tt.m)
+		if s, _, _ := strings.Cut(buf.String(), ".SH"); s != tt.want {
+			t.Errorf("Page(%+v): title = %q; want %q", tt.m, s, tt.want)
+		}
+	}
+}
+
+func TestPrinterCommandSynopsis(t *testing.T) {
+	m := &ManPage{
+		Name:        "cmd",
+		Section:     "1",
+		Kind:        CommandPage,
+		Description: "run commands",
+		Synopsis:    &Synopsis{Command: "cmd", Args: []string{"command"}},
+	}
+	var buf strings.Builder
+	p := newManPrinter(&buf)
+	p.Page(m)
+	want := `.TH cmd 1
+.SH NAME
+cmd \- run commands
+`
+	if s := buf.String(); s != want {
+		t.Errorf("Page(%+v) = %q; want %q", m.Synopsis, s, want)
+	}
+}
+
+func TestPrinterOptionMacros(t *testing.T) {
+	tests := []struct {
+		flags   []*Flag
+		opt, sw bool
+	}{
+		{[]*Flag{{Name: "o", Placeholder: "file"}}, true, false},
+		{[]*Flag{{Name: "v", Kind: BoolFlag}}, false, true},
+		{[]*Flag{{Name: "verbose", Long: true, Kind: BoolFlag}}, false, false},
+		{[]*Flag{{Name: "o", Placeholder: "file"}, {Name: "v", Kind: BoolFlag}}, true, true},
+	}
+	for _, tt := range tests {
+		var buf strings.Builder
+		p := newManPrinter(&buf)
+		p.writeOptions(tt.flags)
+		s := buf.String()
+		if opt := strings.Contains(s, ".de OPT\n"); opt != tt.opt {
+			t.Errorf("writeOptions(%v) defines OPT = %t; want %t", deref(tt.flags), opt, tt.opt)
+		}
+		if sw := strings.Contains(s, ".de SW\n"); sw != tt.sw {
+			t.Errorf("writeOptions(%v) defines SW = %t; want %t", deref(tt.flags), sw, tt.sw)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// badsubcmd is a test package for subcommands that can't be file names.
+package main
+
+import (
+	"flag"
+)
+
+func main() {
+	flag.NewFlagSet("run", flag.ExitOnError).Bool("n", false, "dry run")
+	flag.NewFlagSet("../../escape", flag.ExitOnError).Bool("f", false, "force")
+	flag.NewFlagSet("two words", flag.ExitOnError).Bool("q", false, "quiet")
+	flag.Parse()
+}
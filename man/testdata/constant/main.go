@@ -0,0 +1,27 @@
+// constant is a test package for flags defined with constant expressions.
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+)
+
+type mode string
+
+const (
+	nameFlag   = "dir"
+	defaultDir = "man"
+	usageText  = "specify the output " + "`dir`ectory"
+
+	defaultMode mode = "fast"
+)
+
+func main() {
+	flag.String(nameFlag, defaultDir, usageText)
+	flag.String("mode", string(defaultMode), "set the `mode`")
+	flag.Duration("timeout", 5*time.Second, "wait for `duration`")
+	flag.Float64("ratio", 1.50, "set the `ratio`")
+	flag.String("home", os.Getenv("HOME"), "home `dir`ectory")
+	flag.Parse()
+}
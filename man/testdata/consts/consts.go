@@ -0,0 +1,19 @@
+// Package consts is a test package for constants.
+package consts
+
+// MaxSize is the maximum size of the buffer.
+const MaxSize = 1024
+
+// Mode represents the mode of the file.
+type Mode int
+
+// Modes of the file.
+const (
+	ReadOnly  Mode = iota // open the file read-only
+	WriteOnly             // open the file write-only
+)
+
+// Open opens the file with mode.
+func Open(name string, mode Mode) error {
+	return nil
+}
@@ -0,0 +1,27 @@
+// env is a test package for environment variables.
+//
+// # Environment
+//
+// EDITOR is used to edit the message.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const configKey = "ENV_CONFIG"
+
+// home is the base directory.
+var home = os.Getenv("HOME")
+
+func main() {
+	// ENV_CONFIG is a path to the configuration file.
+	if path, ok := os.LookupEnv(configKey); ok {
+		fmt.Println(path)
+	}
+	fmt.Println(os.Getenv("EDITOR"), os.Getenv("HOME"))
+
+	key := "DYNAMIC_" + os.Args[0]
+	fmt.Println(os.Getenv(key))
+}
@@ -0,0 +1,35 @@
+// exit is a test package for exit statuses.
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	// exitUsage means the arguments are invalid.
+	exitUsage = 2
+
+	exitNotFound = 3 // the file is not found
+
+	// exitDelay is the seconds to wait before exiting.
+	exitDelay = 5
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		os.Exit(exitUsage)
+	}
+	if _, err := os.Stat(os.Args[1]); err != nil {
+		if os.IsNotExist(err) {
+			os.Exit(exitNotFound)
+		}
+		log.Fatalln(err)
+	}
+	defer time.Sleep(exitDelay * time.Second)
+	if len(os.Args) > 2 {
+		// too many files are passed.
+		os.Exit(4)
+	}
+}
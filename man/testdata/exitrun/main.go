@@ -0,0 +1,45 @@
+// exitrun is a test package for exit statuses returned by functions.
+package main
+
+import (
+	"os"
+)
+
+// exitUsage means the arguments are invalid.
+const exitUsage = 2
+
+type status int
+
+const (
+	// statusConflict means the file is modified by another process.
+	statusConflict status = 10
+
+	// statusBusy means the file is locked.
+	statusBusy status = 11
+)
+
+func main() {
+	if len(os.Args) > 2 && os.Args[1] == "-check" {
+		code := check(os.Args[2])
+		os.Exit(int(code))
+	}
+	os.Exit(run())
+}
+
+func run() int {
+	if len(os.Args) < 2 {
+		return exitUsage
+	}
+	if _, err := os.Stat(os.Args[1]); err != nil {
+		// the file is not found.
+		return 3
+	}
+	return 0
+}
+
+func check(file string) status {
+	if _, err := os.Stat(file + ".lock"); err == nil {
+		return statusBusy
+	}
+	return statusConflict
+}
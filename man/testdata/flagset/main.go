@@ -0,0 +1,43 @@
+// flagset is a test package for flag.FlagSet.
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+)
+
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+type command struct {
+	fs      *flag.FlagSet
+	verbose bool
+}
+
+func newFlagSet() *flag.FlagSet {
+	return flag.NewFlagSet("flagset", flag.ExitOnError)
+}
+
+func (c *command) defineFlags() {
+	c.fs.BoolVar(&c.verbose, "v", false, "enable verbose output")
+}
+
+func main() {
+	c := &command{fs: newFlagSet()}
+	c.defineFlags()
+	fs := c.fs
+	out := fs.String("o", "a.out", "write the output to `file`")
+	var includes list
+	fs.Var(&includes, "I", "add `dir` to the include path")
+	fs.Parse(os.Args[1:])
+	_ = out
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"flag"
+)
+
+func init() {
+	flag.Bool("b", false, "declared in init function")
+	flag.Bool("a", false, "declared after b")
+}
@@ -0,0 +1,13 @@
+// order is a test package for the order of flags.
+package main
+
+import (
+	"flag"
+)
+
+var zflag = flag.Bool("z", false, "declared at first in main.go")
+
+func main() {
+	flag.Bool("m", false, "declared in main function")
+	flag.Parse()
+}
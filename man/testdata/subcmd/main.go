@@ -0,0 +1,59 @@
+// subcmd is a test package for subcommands.
+//
+// # Build
+//
+// The build subcommand compiles the sources.
+//
+// # Deploy
+//
+// The deploy subcommand uploads the artifacts to the server.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+)
+
+type command struct {
+	name string
+	fs   *flag.FlagSet
+	run  func(args []string)
+}
+
+func buildCommand() *command {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	out := fs.String("o", "a.out", "write the output to `file`")
+	return &command{
+		name: "build",
+		fs:   fs,
+		run: func(args []string) {
+			log.Println(*out, args)
+		},
+	}
+}
+
+func deployFlags() (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	host := fs.String("host", "localhost", "deploy to `host`")
+	return fs, host
+}
+
+func main() {
+	verbose := flag.Bool("v", false, "enable verbose output")
+	flag.Parse()
+
+	fs, host := deployFlags()
+	fs.Bool("n", false, "dry run")
+	cmds := []*command{
+		buildCommand(),
+		{name: "deploy", fs: fs, run: func(args []string) { log.Println(*host) }},
+	}
+	for _, c := range cmds {
+		if c.name == flag.Arg(0) {
+			c.fs.Parse(os.Args[2:])
+			c.run(c.fs.Args())
+		}
+	}
+	_ = verbose
+}
@@ -0,0 +1,40 @@
+// cobra is a test package for github.com/spf13/cobra.
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "cobra",
+	Short: "cobra is a test command",
+	Long:  "Cobra runs the subcommands.",
+}
+
+var buildCmd = &cobra.Command{
+	Use:   "build [flags] [pkg ...]",
+	Short: "compile packages",
+	Long:  "Build compiles the packages named by the import paths.",
+	Run:   func(cmd *cobra.Command, args []string) {},
+}
+
+func newDeployCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "upload artifacts",
+	}
+	f := cmd.Flags()
+	f.String("host", "localhost", "deploy to `host`")
+	cmd.PersistentFlags().Bool("dry-run", false, "show what would be uploaded")
+	return cmd
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "enable verbose output")
+	buildCmd.Flags().StringP("output", "o", "a.out", "write the output to `file`")
+	rootCmd.AddCommand(buildCmd, newDeployCommand())
+}
+
+func main() {
+	rootCmd.Execute()
+}
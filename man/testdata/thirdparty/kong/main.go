@@ -0,0 +1,23 @@
+// kong is a test package for github.com/alecthomas/kong.
+package main
+
+import (
+	"github.com/alecthomas/kong"
+)
+
+type BuildCmd struct {
+	OutputFile string   `help:"write the output to the file" short:"o"`
+	Pkgs       []string `arg:"" optional:""`
+}
+
+var cli struct {
+	Verbose  bool   `help:"enable verbose output" short:"v" env:"KONG_VERBOSE"`
+	HTTPPort int    `help:"listen on the port" placeholder:"PORT" aliases:"port" default:"8080"`
+	secret   string `help:"unexported field"`
+
+	Build BuildCmd `cmd:"" help:"compile packages"`
+}
+
+func main() {
+	kong.Parse(&cli)
+}
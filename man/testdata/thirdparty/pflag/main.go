@@ -0,0 +1,29 @@
+// pflag is a test package for github.com/spf13/pflag.
+package main
+
+import (
+	"net/netip"
+
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	var verbose bool
+	pflag.BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	pflag.StringP("output", "o", "a.out", "write the output to `file`")
+	pflag.StringSlice("tags", nil, "comma-separated list of build tags")
+	pflag.Count("debug", "increase debug level")
+	pflag.IP("addr", nil, "listen address")
+	pflag.IPP("bind", "b", nil, "bind address")
+	var level logLevel
+	pflag.VarP(&level, "level", "l", "set the log level")
+	var peer netip.Addr
+	pflag.TextVar(&peer, "peer", netip.MustParseAddr("192.0.2.1"), "peer address")
+	pflag.Parse()
+}
+
+type logLevel string
+
+func (l *logLevel) String() string     { return string(*l) }
+func (l *logLevel) Set(s string) error { *l = logLevel(s); return nil }
+func (l *logLevel) Type() string       { return "level" }
@@ -0,0 +1,43 @@
+// urfave is a test package for github.com/urfave/cli/v2.
+package main
+
+import (
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := &cli.App{
+		Name: "urfave",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "load configuration from `FILE`",
+				EnvVars: []string{"URFAVE_CONFIG"},
+			},
+			&cli.BoolFlag{
+				Name:  "verbose",
+				Usage: "enable verbose output",
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "build",
+				Usage: "compile packages",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "output", Aliases: []string{"o"}, Usage: "write the output"},
+				},
+			},
+			{
+				Name:  os.Getenv("URFAVE_PLUGIN"),
+				Usage: "run the plugin",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "plugin-dir", Usage: "load the plugin from `DIR`"},
+				},
+			},
+		},
+	}
+	app.Run(os.Args)
+}
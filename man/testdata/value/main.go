@@ -0,0 +1,48 @@
+// value is a test package for flags backed by flag.Value.
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, strings.Split(s, ",")...)
+	return nil
+}
+
+type traceValue bool
+
+func (v *traceValue) String() string     { return "" }
+func (v *traceValue) Set(s string) error { *v = true; return nil }
+func (v *traceValue) IsBoolFlag() bool   { return true }
+
+func main() {
+	var (
+		tags  stringList
+		trace traceValue
+		addr  net.IP
+		peer  netip.Addr
+		level slog.Level
+	)
+	flag.Var(&tags, "tags", "comma-separated list of tags")
+	flag.Var(&trace, "trace", "enable tracing")
+	flag.TextVar(&addr, "addr", net.IPv4(127, 0, 0, 1), "listen address")
+	flag.TextVar(&peer, "peer", netip.MustParseAddr("192.0.2.1"), "peer address")
+
+	// The text of the constant is "INFO", but it is known only by running MarshalText.
+	flag.TextVar(&level, "level", slog.LevelInfo, "log level")
+	flag.BoolFunc("debug", "enable debug log", func(string) error { return nil })
+	flag.Int("n", 3, "number of workers")
+	flag.Func("define", "define `name=value`", func(string) error { return nil })
+	flag.Parse()
+}
@@ -0,0 +1,133 @@
+package man
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+const urfavePath = "github.com/urfave/cli/v2"
+
+// findUrfaveFlags retrieves flags declared as composite literals of github.com/urfave/cli/v2 package.
+// Flags written in Flags field of cli.Command belong to the subcommand.
+// Arguments that are not constants, and commands that have no constant names, are reported to warn unless it is nil.
+func findUrfaveFlags(info *types.Info, fset *token.FileSet, files []*ast.File, warn func(msg string)) *commandInfo {
+	var (
+		flags []*Flag
+		cmds  []*subcommand
+	)
+	var visit func(node ast.Node, cmd string)
+	visit = func(node ast.Node, cmd string) {
+		ast.Inspect(node, func(node ast.Node) bool {
+			lit, ok := node.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			t := info.TypeOf(lit)
+			switch {
+			case isNamed(t, urfavePath, "Command"):
+				// The command that has no name can't have its manual,
+				// and its flags must not be attributed to the parent.
+				name := exprStr(info, fieldValue(lit, "Name"))
+				if name == "" {
+					if warn != nil {
+						warn(fmt.Sprintf("%v: the command has no constant name; ignored", fset.Position(lit.Pos())))
+					}
+					return false
+				}
+				cmds = append(cmds, &subcommand{
+					Name:     name,
+					Synopsis: exprStr(info, fieldValue(lit, "Usage")),
+					Doc:      exprStr(info, fieldValue(lit, "Description")),
+				})
+				for _, elt := range lit.Elts {
+					visit(elt, name)
+				}
+				return false
+			case isUrfaveFlag(t):
+				flg := urfaveFlag(info, fset, lit, urfaveType(t), warn)
+				flg.FlagSet = cmd
+				flags = append(flags, flg)
+				return false
+			}
+			return true
+		})
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			visit(decl, "")
+		}
+	}
+	own, subcmds := groupFlags("", flags)
+	return &commandInfo{
+		Flags:       own,
+		Subcommands: mergeSubcommands(cmds, subcmds),
+	}
+}
+
+func urfaveFlag(info *types.Info, fset *token.FileSet, lit *ast.CompositeLit, typ string, warn func(msg string)) *Flag {
+	flg := &flag.Flag{
+		Name:  argStr(info, fset, fieldValue(lit, "Name"), "name", warn),
+		Usage: argStr(info, fset, fieldValue(lit, "Usage"), "usage", warn),
+	}
+	name, usage := flag.UnquoteUsage(flg)
+	if typ == "bool" {
+		name = ""
+	}
+	def := exprStr(info, fieldValue(lit, "DefaultText"))
+	if def == "" {
+		def = nonZero(typ, exprStr(info, fieldValue(lit, "Value")))
+	}
+	return &Flag{
+		Name:        flg.Name,
+		Long:        true,
+		Kind:        switchKind(typ),
+		Aliases:     stringsExpr(info, fieldValue(lit, "Aliases")),
+		Env:         stringsExpr(info, fieldValue(lit, "EnvVars")),
+		Placeholder: name,
+		Usage:       usage,
+		Type:        typ,
+		Default:     def,
+	}
+}
+
+// urfaveType returns the type name of the flag value from the flag type t, such as cli.StringFlag.
+func urfaveType(t types.Type) string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	return lowerCamel(strings.TrimSuffix(t.(*types.Named).Obj().Name(), "Flag"))
+}
+
+// isUrfaveFlag reports whether t is one of flag types, such as cli.StringFlag, of urfave/cli.
+func isUrfaveFlag(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	if obj.Pkg() == nil || obj.Pkg().Path() != urfavePath {
+		return false
+	}
+	_, ok = named.Underlying().(*types.Struct)
+	return ok && strings.HasSuffix(obj.Name(), "Flag")
+}
+
+// stringsExpr returns the elements of []string literal expr.
+func stringsExpr(info *types.Info, expr ast.Expr) []string {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	var a []string
+	for _, elt := range lit.Elts {
+		a = append(a, exprStr(info, elt))
+	}
+	return a
+}
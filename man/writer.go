@@ -1,4 +1,4 @@
-package main
+package man
 
 import (
 	"bytes"
@@ -6,20 +6,20 @@ import (
 	"slices"
 )
 
-type ExpWriter struct {
+type expWriter struct {
 	w      io.Writer
 	prefix []byte
 }
 
-func NewExpWriter(w io.Writer) *ExpWriter {
-	return &ExpWriter{w, nil}
+func newExpWriter(w io.Writer) *expWriter {
+	return &expWriter{w, nil}
 }
 
-func (w *ExpWriter) NeedNextToken(token string) {
+func (w *expWriter) NeedNextToken(token string) {
 	w.prefix = []byte(token)
 }
 
-func (w *ExpWriter) Write(data []byte) (n int, err error) {
+func (w *expWriter) Write(data []byte) (n int, err error) {
 	n, err = w.writePrefix(data)
 	if err != nil {
 		return n, err
@@ -31,7 +31,7 @@ func (w *ExpWriter) Write(data []byte) (n int, err error) {
 	return n + m, nil
 }
 
-func (w *ExpWriter) writePrefix(data []byte) (int, error) {
+func (w *expWriter) writePrefix(data []byte) (int, error) {
 	if len(data) == 0 {
 		return 0, nil
 	}
@@ -64,15 +64,15 @@ func diffPrefix(data, prefix []byte) int {
 	return len(prefix)
 }
 
-type Heading struct {
+type headingWriter struct {
 	w io.Writer
 }
 
-func NewHeading(w io.Writer) *Heading {
-	return &Heading{w}
+func newHeadingWriter(w io.Writer) *headingWriter {
+	return &headingWriter{w}
 }
 
-func (w *Heading) Write(p []byte) (int, error) {
+func (w *headingWriter) Write(p []byte) (int, error) {
 	var (
 		buf bytes.Buffer
 		n   int
@@ -1,4 +1,4 @@
-package main
+package man
 
 import (
 	"fmt"
@@ -8,7 +8,7 @@ import (
 
 func TestExpWriterWrite(t *testing.T) {
 	var buf strings.Builder
-	p := NewExpWriter(&buf)
+	p := newExpWriter(&buf)
 	s := "test"
 	fmt.Fprintf(p, "%s", s)
 	if v := buf.String(); v != s {
@@ -32,7 +32,7 @@ func TestPrinterWriteWithPrefix(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			var buf strings.Builder
-			w := NewExpWriter(&buf)
+			w := newExpWriter(&buf)
 			w.NeedNextToken(tt.prefix)
 			fmt.Fprint(w, tt.s)
 			if v := buf.String(); v != tt.want {